@@ -48,6 +48,10 @@ func (i *IntegerLiteral) String() string       { return i.Token.Literal }
 
 type Program struct {
 	Statements []Statement
+	// all comments in the source, in order of appearance.
+	// They are kept for tools such as the formatter and
+	// are not part of Statements.
+	Comments []*CommentGroup
 }
 
 func (p *Program) TokenLiteral() string {
@@ -72,6 +76,38 @@ type IndexExpression struct {
 	Index Expression
 }
 
+// SliceExpression is left[start:end:step] where
+// any of start, end and step may be omitted (nil)
+type SliceExpression struct {
+	Token token.Token // the '[' token
+	Left  Expression
+	Start Expression
+	End   Expression
+	Step  Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
 type StringLiteral struct {
 	Token token.Token
 	Value string
@@ -93,10 +129,13 @@ func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
+// LetStatement binds either a single name or, if Name is
+// nil, the identifiers of an ArrayPattern or HashPattern
 type LetStatement struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token
+	Name    *Identifier
+	Pattern Expression
+	Value   Expression
 }
 
 func (ls *LetStatement) statementNode()       {}
@@ -104,7 +143,11 @@ func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Name != nil {
+		out.WriteString(ls.Name.String())
+	} else {
+		out.WriteString(ls.Pattern.String())
+	}
 	out.WriteString(" = ")
 	if ls.Value != nil {
 		out.WriteString(ls.Value.String())
@@ -132,6 +175,24 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// ThrowStatement raises its value as an error
+type ThrowStatement struct {
+	Token token.Token // the 'throw' token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ts.TokenLiteral() + " ")
+	if ts.Value != nil {
+		out.WriteString(ts.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
@@ -252,6 +313,122 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// TryExpression is try { } catch (param) { } finally { } where
+// either the catch or the finally part may be left out
+type TryExpression struct {
+	Token   token.Token     // the 'try' token
+	Block   *BlockStatement // the try branch
+	Param   *Identifier     // bound to the caught error
+	Catch   *BlockStatement // nil without catch
+	Finally *BlockStatement // nil without finally
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("try ")
+	out.WriteString(te.Block.String())
+	if te.Catch != nil {
+		out.WriteString(" catch (" + te.Param.String() + ") ")
+		out.WriteString(te.Catch.String())
+	}
+	if te.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(te.Finally.String())
+	}
+	return out.String()
+}
+
+// MatchExpression evaluates the body of the first arm whose
+// pattern matches the subject and whose guard, if any, holds
+type MatchExpression struct {
+	Token   token.Token // the 'match' token
+	Subject Expression
+	Arms    []MatchArm // in source order
+}
+
+type MatchArm struct {
+	// a literal, an identifier to bind, _ to match
+	// anything or an ArrayPattern or HashPattern
+	Pattern Expression
+	Guard   Expression // nil without guard
+	Body    Node       // an Expression or a *BlockStatement
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	arms := []string{}
+	for _, arm := range me.Arms {
+		s := arm.Pattern.String()
+		if arm.Guard != nil {
+			s += " if " + arm.Guard.String()
+		}
+		arms = append(arms, s+" => "+arm.Body.String())
+	}
+	out.WriteString("match (" + me.Subject.String() + ") { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+	return out.String()
+}
+
+// ArrayPattern matches arrays of as many elements as it has, each
+// matching the pattern at its index. With Rest, longer arrays match
+// too and Rest is bound to the remaining elements.
+type ArrayPattern struct {
+	Token    token.Token // the '[' token
+	Elements []Expression
+	Rest     *Identifier // nil without ...rest
+}
+
+func (ap *ArrayPattern) expressionNode()      {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	elements := []string{}
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// DefaultPattern is an element of an ArrayPattern or a value of a
+// HashPattern that also matches if the element or key is missing,
+// in which case Default is matched against Pattern instead
+type DefaultPattern struct {
+	Token   token.Token // the '=' token
+	Pattern Expression
+	Default Expression
+}
+
+func (dp *DefaultPattern) expressionNode()      {}
+func (dp *DefaultPattern) TokenLiteral() string { return dp.Token.Literal }
+func (dp *DefaultPattern) String() string {
+	return dp.Pattern.String() + " = " + dp.Default.String()
+}
+
+// HashPattern matches hashes holding each of its keys, which are
+// literals, with a value matching the pattern paired with the key.
+// Other keys of the hash are ignored.
+type HashPattern struct {
+	Token token.Token // the '{' token
+	Pairs []HashPair  // in source order
+}
+
+func (hp *HashPattern) expressionNode()      {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	pairs := []string{}
+	for _, pair := range hp.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
 type FunctionLiteral struct {
 	Token      token.Token // the 'fn' token
 	Parameters []*Identifier
@@ -295,9 +472,27 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// ImportExpression is import(path), evaluating
+// to the module loaded from the file at path
+type ImportExpression struct {
+	Token token.Token // the 'import' token
+	Path  Expression
+}
+
+func (ie *ImportExpression) expressionNode()      {}
+func (ie *ImportExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *ImportExpression) String() string {
+	return ie.TokenLiteral() + "(" + ie.Path.String() + ")"
+}
+
 type HashLiteral struct {
 	Token token.Token // the '{' token
-	Pairs map[Expression]Expression
+	Pairs []HashPair  // in source order
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -305,11 +500,51 @@ func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
 	out.WriteString("}")
 	return out.String()
 }
+
+// A Comment is a single // comment. The literal of its token
+// is the comment as written, including the leading slashes.
+type Comment struct {
+	Token token.Token // the token.COMMENT token
+}
+
+func (c *Comment) TokenLiteral() string { return c.Token.Literal }
+func (c *Comment) String() string       { return c.Token.Literal }
+
+// A CommentGroup is a sequence of comments on consecutive
+// lines with no other tokens or empty lines between them.
+type CommentGroup struct {
+	List []*Comment
+}
+
+func (g *CommentGroup) TokenLiteral() string {
+	if len(g.List) > 0 {
+		return g.List[0].TokenLiteral()
+	}
+	return ""
+}
+
+func (g *CommentGroup) String() string {
+	lines := []string{}
+	for _, c := range g.List {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Text returns the text of the comment group with the
+// comment markers and surrounding whitespace removed
+func (g *CommentGroup) Text() string {
+	lines := []string{}
+	for _, c := range g.List {
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(c.Token.Literal, "//")))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/lindeneg/monkey/token"
@@ -26,3 +27,32 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
+
+func TestFprint(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&ReturnStatement{
+				Token: token.Token{Type: token.RETURN, Literal: "return", Line: 1, Col: 1},
+				ReturnValue: &IntegerLiteral{
+					Token: token.Token{Type: token.INT, Literal: "5", Line: 1, Col: 8},
+					Value: 5,
+				},
+			},
+		},
+	}
+	expected := `Program
+  Statements: [1]
+    ReturnStatement "return" (1:1)
+      ReturnValue:
+        IntegerLiteral "5" (1:8)
+          Value: 5
+  Comments: [0]
+`
+	var out bytes.Buffer
+	if err := Fprint(&out, program); err != nil {
+		t.Fatalf("Fprint returned error: %s", err)
+	}
+	if out.String() != expected {
+		t.Errorf("Fprint wrong. expected=%q, got=%q", expected, out.String())
+	}
+}
@@ -0,0 +1,362 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/lindeneg/monkey/token"
+)
+
+// The JSON schema for a node is an object with the following members,
+// in this order:
+//
+//	"kind"   the Go type name of the node, e.g. "InfixExpression"
+//	"span"   {"start": {"line", "col"}, "end": {"line", "col"}}
+//	"token"  {"type", "literal", "line", "col"} (absent on Program)
+//
+// followed by one member per field of the node, named as the Go field
+// with a lowercase first letter. Child nodes are encoded recursively,
+// lists as arrays and HashLiteral pairs as an array of {"key", "value"}
+// objects in source order. MatchExpression arms likewise become an
+// array of {"pattern", "guard", "body"} objects.
+//
+// A span runs from the start of the first token recorded in the node
+// to the end of the last one, so closing delimiters such as ) or }
+// that are not kept in the tree are not covered.
+
+// nodeKinds holds every concrete node type that can be decoded
+var nodeKinds = map[string]reflect.Type{}
+
+func init() {
+	for _, n := range []Node{
+		&Program{},
+		&Identifier{},
+		&IntegerLiteral{},
+		&StringLiteral{},
+		&Boolean{},
+		&IndexExpression{},
+		&SliceExpression{},
+		&LetStatement{},
+		&ReturnStatement{},
+		&ThrowStatement{},
+		&ExpressionStatement{},
+		&BlockStatement{},
+		&ArrayLiteral{},
+		&PrefixExpression{},
+		&InfixExpression{},
+		&IfExpression{},
+		&TryExpression{},
+		&MatchExpression{},
+		&ArrayPattern{},
+		&HashPattern{},
+		&DefaultPattern{},
+		&FunctionLiteral{},
+		&CallExpression{},
+		&ImportExpression{},
+		&HashLiteral{},
+		&CommentGroup{},
+		&Comment{},
+	} {
+		t := reflect.TypeOf(n).Elem()
+		nodeKinds[t.Name()] = t
+	}
+}
+
+type Position struct {
+	Line int `json:"line"`
+	Col  int `json:"col"`
+}
+
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// SpanOf returns the source span covered by the tokens of node.
+// The zero Span is returned if node holds no positioned tokens.
+func SpanOf(node Node) Span {
+	var span Span
+	collectSpan(reflect.ValueOf(node), &span)
+	return span
+}
+
+func collectSpan(v reflect.Value, span *Span) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		collectSpan(v.Elem(), span)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectSpan(v.Index(i), span)
+		}
+	case reflect.Struct:
+		if v.Type() == tokenType {
+			tok := v.Interface().(token.Token)
+			if tok.Line == 0 {
+				return
+			}
+			start := Position{Line: tok.Line, Col: tok.Col}
+			end := tokenEnd(tok)
+			if span.Start.Line == 0 || before(start, span.Start) {
+				span.Start = start
+			}
+			if before(span.End, end) {
+				span.End = end
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectSpan(v.Field(i), span)
+			}
+		}
+	}
+}
+
+// tokenEnd returns the position just after tok in the source
+func tokenEnd(tok token.Token) Position {
+	text := tok.Literal
+	if tok.Type == token.STRING {
+		text = `"` + text + `"`
+	}
+	end := Position{Line: tok.Line, Col: tok.Col + len(text)}
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		end.Line += strings.Count(text, "\n")
+		end.Col = len(text) - i
+	}
+	return end
+}
+
+func before(a, b Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Col < b.Col
+}
+
+// Marshal encodes node using the JSON schema described above
+func Marshal(node Node) ([]byte, error) {
+	return json.Marshal(encodeNode(reflect.ValueOf(node)))
+}
+
+// MarshalIndent is like Marshal but indents the output
+func MarshalIndent(node Node, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(encodeNode(reflect.ValueOf(node)), prefix, indent)
+}
+
+// jsonObject is a JSON object that keeps its members in insertion order
+type jsonObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *jsonObject) set(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		k, _ := json.Marshal(key)
+		out.Write(k)
+		out.WriteString(":")
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		out.Write(v)
+	}
+	out.WriteString("}")
+	return out.Bytes(), nil
+}
+
+// encodeNode converts v, expected to hold a Node, into its JSON form
+func encodeNode(v reflect.Value) interface{} {
+	v = indirectInterface(v)
+	if !v.IsValid() || v.IsNil() {
+		return nil
+	}
+	s := v.Elem()
+	out := &jsonObject{}
+	out.set("kind", s.Type().Name())
+	out.set("span", SpanOf(v.Interface().(Node)))
+	for i := 0; i < s.NumField(); i++ {
+		field := s.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type == tokenType {
+			out.set("token", encodeToken(s.Field(i).Interface().(token.Token)))
+			continue
+		}
+		out.set(jsonName(field.Name), encodeValue(s.Field(i)))
+	}
+	return out
+}
+
+func encodeToken(tok token.Token) interface{} {
+	out := &jsonObject{}
+	out.set("type", tok.Type)
+	out.set("literal", tok.Literal)
+	out.set("line", tok.Line)
+	out.set("col", tok.Col)
+	return out
+}
+
+func encodeValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return encodeNode(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = encodeValue(v.Index(i))
+		}
+		return out
+	case reflect.Struct:
+		out := &jsonObject{}
+		for i := 0; i < v.NumField(); i++ {
+			out.set(jsonName(v.Type().Field(i).Name), encodeValue(v.Field(i)))
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// Unmarshal decodes a node previously encoded with Marshal
+func Unmarshal(data []byte) (Node, error) {
+	v, err := decodeNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	return v.Interface().(Node), nil
+}
+
+// decodeNode returns a pointer to a freshly decoded node,
+// or the zero Value if data is null
+func decodeNode(data []byte) (reflect.Value, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return reflect.Value{}, err
+	}
+	if members == nil {
+		return reflect.Value{}, nil
+	}
+	var kind string
+	if err := json.Unmarshal(members["kind"], &kind); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid node kind: %w", err)
+	}
+	t, ok := nodeKinds[kind]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("unknown node kind %q", kind)
+	}
+	v := reflect.New(t)
+	s := v.Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type == tokenType {
+			if err := decodeToken(members["token"], s.Field(i)); err != nil {
+				return reflect.Value{}, fmt.Errorf("%s.Token: %w", kind, err)
+			}
+			continue
+		}
+		raw, ok := members[jsonName(field.Name)]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(raw, s.Field(i)); err != nil {
+			return reflect.Value{}, fmt.Errorf("%s.%s: %w", kind, field.Name, err)
+		}
+	}
+	return v, nil
+}
+
+func decodeToken(data []byte, dst reflect.Value) error {
+	var tok struct {
+		Type    token.TokenType `json:"type"`
+		Literal string          `json:"literal"`
+		Line    int             `json:"line"`
+		Col     int             `json:"col"`
+	}
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return err
+	}
+	dst.Set(reflect.ValueOf(token.Token{
+		Type:    tok.Type,
+		Literal: tok.Literal,
+		Line:    tok.Line,
+		Col:     tok.Col,
+	}))
+	return nil
+}
+
+// decodeValue decodes data into dst according to the type of dst
+func decodeValue(data []byte, dst reflect.Value) error {
+	switch dst.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		v, err := decodeNode(data)
+		if err != nil || !v.IsValid() {
+			return err
+		}
+		if !v.Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("%s is not assignable to %s", v.Type(), dst.Type())
+		}
+		dst.Set(v)
+		return nil
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		if items == nil {
+			return nil
+		}
+		out := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(item, out.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+		return nil
+	case reflect.Struct:
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(data, &members); err != nil {
+			return err
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			raw, ok := members[jsonName(dst.Type().Field(i).Name)]
+			if !ok {
+				continue
+			}
+			if err := decodeValue(raw, dst.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return json.Unmarshal(data, dst.Addr().Interface())
+	}
+}
+
+// jsonName lowercases the first letter of a Go field name
+func jsonName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,106 @@
+package ast_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lindeneg/monkey/ast"
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/parser"
+)
+
+// inputs taken from parser_test.go
+var roundTripInputs = []string{
+	"let x = 5;",
+	"let y = true;",
+	"let foobar = y;",
+	"return 5;",
+	"return foobar;",
+	"foobar;",
+	`"hello world";`,
+	"myArray[1 + 1]",
+	"myArray[1:]; myArray[:-1]; myArray[::2]; myArray[a:b:c]",
+	`let lib = import("lib.monkey"); import(path)["x"]`,
+	"[1, 2 * 2, 3 + 3]",
+	`{"one": 1, "two": 2, "three": 3}`,
+	`{true: 1, false: 2}`,
+	`{1: "hello", 2: "there"}`,
+	`{"one": 0 + 1, "two": 10 - 8, "three": 15 / 5}`,
+	"{}",
+	"!5; -15; !foobar; !true;",
+	"a + b * c + d / e - f",
+	"5 > 4 == 3 < 4; 3 <= 5 != 5 >= 3",
+	"add(a * b[2], b[1], 2 * [1, 2][1])",
+	"if (x < y) { x }",
+	"if (x < y) { x } else { y }",
+	`let [a, b = 1, ...rest] = arr; let {"name": n, "pos": [x, _]} = p;`,
+	`match (x) { 1 => "one", [a, {"k": _}] if a > 1 => { a } -2 => ({"a": 1}), _ => 0 }`,
+	`try { f(x) } catch (e) { throw e["message"]; } finally { g() }; try { 1 } finally { 2 }`,
+	"fn(x, y) { x + y; }",
+	"fn() {};",
+	"add(1, 2 * 3, 4 + 5);",
+	"let newAdder = fn(x) {\n  fn(y) { x + y };\n};\nnewAdder(2)(3);",
+	"// leading\n// group\nlet x = 5; // trailing\n\n// last",
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, input := range roundTripInputs {
+		p := parser.New(lexer.NewLexer(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("parser errors for %q: %v", input, p.Errors())
+		}
+		data, err := ast.Marshal(program)
+		if err != nil {
+			t.Fatalf("Marshal(%q) returned error: %s", input, err)
+		}
+		node, err := ast.Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal(%q) returned error: %s", input, err)
+		}
+		decoded, ok := node.(*ast.Program)
+		if !ok {
+			t.Fatalf("decoded node is not *ast.Program. got=%T", node)
+		}
+		if len(decoded.Comments) != len(program.Comments) {
+			t.Errorf("wrong number of comment groups for %q. expected=%d, got=%d",
+				input, len(program.Comments), len(decoded.Comments))
+		}
+		if len(decoded.Statements) != len(program.Statements) {
+			t.Errorf("wrong number of statements for %q. expected=%d, got=%d",
+				input, len(program.Statements), len(decoded.Statements))
+		}
+		again, err := ast.Marshal(decoded)
+		if err != nil {
+			t.Fatalf("Marshal(decoded %q) returned error: %s", input, err)
+		}
+		if !bytes.Equal(data, again) {
+			t.Errorf("round trip of %q not equal.\nfirst=%s\nsecond=%s", input, data, again)
+		}
+	}
+}
+
+func TestJSONSpan(t *testing.T) {
+	input := "let s = \"hi\";\nadd(1, 22)"
+	program := parser.New(lexer.NewLexer(input)).ParseProgram()
+	tests := []struct {
+		node     ast.Node
+		expected ast.Span
+	}{
+		{program, ast.Span{Start: ast.Position{Line: 1, Col: 1}, End: ast.Position{Line: 2, Col: 10}}},
+		{program.Statements[0], ast.Span{Start: ast.Position{Line: 1, Col: 1}, End: ast.Position{Line: 1, Col: 13}}},
+		{program.Statements[1], ast.Span{Start: ast.Position{Line: 2, Col: 1}, End: ast.Position{Line: 2, Col: 10}}},
+	}
+	for i, tt := range tests {
+		if span := ast.SpanOf(tt.node); span != tt.expected {
+			t.Errorf("tests[%d] - span wrong. expected=%+v, got=%+v", i, tt.expected, span)
+		}
+	}
+}
+
+func TestJSONUnknownKind(t *testing.T) {
+	_, err := ast.Unmarshal([]byte(`{"kind": "WhileLoop"}`))
+	if err == nil || err.Error() != `unknown node kind "WhileLoop"` {
+		t.Errorf("expected unknown node kind error, got=%v", err)
+	}
+}
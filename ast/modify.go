@@ -0,0 +1,127 @@
+package ast
+
+import "reflect"
+
+type ModifierFunc func(Node) Node
+
+// Modify traverses an AST in depth-first order and replaces every
+// node with the result of calling modifier on it. Children are
+// modified before their parent, so modifier always sees a node
+// whose children have already been replaced.
+//
+// If modifier returns a node that does not fit where the original
+// was, e.g. a Statement in place of an Expression, the slot is left
+// as the zero value of its type. The comments of a Program are
+// modified after its statements. Nil nodes, including the typed nil
+// pointers the parser produces on syntax errors, are returned as they
+// are without calling modifier.
+func Modify(node Node, modifier ModifierFunc) Node {
+	if isNil(node) {
+		return node
+	}
+	switch n := node.(type) {
+	case *Program:
+		for i, s := range n.Statements {
+			n.Statements[i], _ = Modify(s, modifier).(Statement)
+		}
+		for i, g := range n.Comments {
+			n.Comments[i], _ = Modify(g, modifier).(*CommentGroup)
+		}
+	case *CommentGroup:
+		for i, c := range n.List {
+			n.List[i], _ = Modify(c, modifier).(*Comment)
+		}
+	case *BlockStatement:
+		for i, s := range n.Statements {
+			n.Statements[i], _ = Modify(s, modifier).(Statement)
+		}
+	case *LetStatement:
+		n.Name, _ = Modify(n.Name, modifier).(*Identifier)
+		n.Pattern = modifyExpression(n.Pattern, modifier)
+		n.Value = modifyExpression(n.Value, modifier)
+	case *ReturnStatement:
+		n.ReturnValue = modifyExpression(n.ReturnValue, modifier)
+	case *ThrowStatement:
+		n.Value = modifyExpression(n.Value, modifier)
+	case *ExpressionStatement:
+		n.Expression = modifyExpression(n.Expression, modifier)
+	case *PrefixExpression:
+		n.Right = modifyExpression(n.Right, modifier)
+	case *InfixExpression:
+		n.Left = modifyExpression(n.Left, modifier)
+		n.Right = modifyExpression(n.Right, modifier)
+	case *IndexExpression:
+		n.Left = modifyExpression(n.Left, modifier)
+		n.Index = modifyExpression(n.Index, modifier)
+	case *ImportExpression:
+		n.Path = modifyExpression(n.Path, modifier)
+	case *SliceExpression:
+		n.Left = modifyExpression(n.Left, modifier)
+		n.Start = modifyExpression(n.Start, modifier)
+		n.End = modifyExpression(n.End, modifier)
+		n.Step = modifyExpression(n.Step, modifier)
+	case *IfExpression:
+		n.Condition = modifyExpression(n.Condition, modifier)
+		n.Consequence, _ = Modify(n.Consequence, modifier).(*BlockStatement)
+		n.Alternative, _ = Modify(n.Alternative, modifier).(*BlockStatement)
+	case *TryExpression:
+		n.Block, _ = Modify(n.Block, modifier).(*BlockStatement)
+		n.Param, _ = Modify(n.Param, modifier).(*Identifier)
+		n.Catch, _ = Modify(n.Catch, modifier).(*BlockStatement)
+		n.Finally, _ = Modify(n.Finally, modifier).(*BlockStatement)
+	case *MatchExpression:
+		n.Subject = modifyExpression(n.Subject, modifier)
+		for i, arm := range n.Arms {
+			n.Arms[i].Pattern = modifyExpression(arm.Pattern, modifier)
+			n.Arms[i].Guard = modifyExpression(arm.Guard, modifier)
+			n.Arms[i].Body = Modify(arm.Body, modifier)
+		}
+	case *ArrayPattern:
+		for i, e := range n.Elements {
+			n.Elements[i] = modifyExpression(e, modifier)
+		}
+		n.Rest, _ = Modify(n.Rest, modifier).(*Identifier)
+	case *DefaultPattern:
+		n.Pattern = modifyExpression(n.Pattern, modifier)
+		n.Default = modifyExpression(n.Default, modifier)
+	case *HashPattern:
+		for i, pair := range n.Pairs {
+			n.Pairs[i].Key = modifyExpression(pair.Key, modifier)
+			n.Pairs[i].Value = modifyExpression(pair.Value, modifier)
+		}
+	case *FunctionLiteral:
+		for i, p := range n.Parameters {
+			n.Parameters[i], _ = Modify(p, modifier).(*Identifier)
+		}
+		n.Body, _ = Modify(n.Body, modifier).(*BlockStatement)
+	case *CallExpression:
+		n.Function = modifyExpression(n.Function, modifier)
+		for i, a := range n.Arguments {
+			n.Arguments[i] = modifyExpression(a, modifier)
+		}
+	case *ArrayLiteral:
+		for i, e := range n.Elements {
+			n.Elements[i] = modifyExpression(e, modifier)
+		}
+	case *HashLiteral:
+		for i, pair := range n.Pairs {
+			n.Pairs[i].Key = modifyExpression(pair.Key, modifier)
+			n.Pairs[i].Value = modifyExpression(pair.Value, modifier)
+		}
+	}
+	return modifier(node)
+}
+
+func modifyExpression(e Expression, modifier ModifierFunc) Expression {
+	modified, _ := Modify(e, modifier).(Expression)
+	return modified
+}
+
+// isNil reports whether node is nil or a typed nil pointer
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
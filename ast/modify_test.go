@@ -0,0 +1,228 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lindeneg/monkey/token"
+)
+
+func TestModify(t *testing.T) {
+	one := func() Expression { return &IntegerLiteral{Value: 1} }
+	two := func() Expression { return &IntegerLiteral{Value: 2} }
+
+	turnOneIntoTwo := func(node Node) Node {
+		integer, ok := node.(*IntegerLiteral)
+		if !ok {
+			return node
+		}
+		if integer.Value != 1 {
+			return node
+		}
+		integer.Value = 2
+		return integer
+	}
+
+	tests := []struct {
+		input    Node
+		expected Node
+	}{
+		{
+			one(),
+			two(),
+		},
+		{
+			&Program{
+				Statements: []Statement{
+					&ExpressionStatement{Expression: one()},
+				},
+			},
+			&Program{
+				Statements: []Statement{
+					&ExpressionStatement{Expression: two()},
+				},
+			},
+		},
+		{
+			&InfixExpression{Left: one(), Operator: "+", Right: two()},
+			&InfixExpression{Left: two(), Operator: "+", Right: two()},
+		},
+		{
+			&InfixExpression{Left: two(), Operator: "+", Right: one()},
+			&InfixExpression{Left: two(), Operator: "+", Right: two()},
+		},
+		{
+			&PrefixExpression{Operator: "-", Right: one()},
+			&PrefixExpression{Operator: "-", Right: two()},
+		},
+		{
+			&IndexExpression{Left: one(), Index: one()},
+			&IndexExpression{Left: two(), Index: two()},
+		},
+		{
+			&IfExpression{
+				Condition: one(),
+				Consequence: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+				Alternative: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+			},
+			&IfExpression{
+				Condition: two(),
+				Consequence: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+				Alternative: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+			},
+		},
+		{
+			&ReturnStatement{ReturnValue: one()},
+			&ReturnStatement{ReturnValue: two()},
+		},
+		{
+			&LetStatement{Value: one()},
+			&LetStatement{Value: two()},
+		},
+		{
+			&FunctionLiteral{
+				Parameters: []*Identifier{},
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+			},
+			&FunctionLiteral{
+				Parameters: []*Identifier{},
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+			},
+		},
+		{
+			&CallExpression{Function: one(), Arguments: []Expression{one(), two()}},
+			&CallExpression{Function: two(), Arguments: []Expression{two(), two()}},
+		},
+		{
+			&ArrayLiteral{Elements: []Expression{one(), one()}},
+			&ArrayLiteral{Elements: []Expression{two(), two()}},
+		},
+	}
+
+	for _, tt := range tests {
+		modified := Modify(tt.input, turnOneIntoTwo)
+		if !reflect.DeepEqual(modified, tt.expected) {
+			t.Errorf("not equal. got=%#v, want=%#v", modified, tt.expected)
+		}
+	}
+
+	hashLiteral := &HashLiteral{
+		Pairs: []HashPair{
+			{Key: one(), Value: one()},
+			{Key: one(), Value: one()},
+		},
+	}
+	Modify(hashLiteral, turnOneIntoTwo)
+	for _, pair := range hashLiteral.Pairs {
+		key, _ := pair.Key.(*IntegerLiteral)
+		if key.Value != 2 {
+			t.Errorf("value is not %d, got=%d", 2, key.Value)
+		}
+		val, _ := pair.Value.(*IntegerLiteral)
+		if val.Value != 2 {
+			t.Errorf("value is not %d, got=%d", 2, val.Value)
+		}
+	}
+}
+
+// the parser leaves typed nil pointers in the tree on syntax errors
+func TestModifyTypedNil(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			(*LetStatement)(nil),
+			&LetStatement{Name: (*Identifier)(nil), Value: (*IntegerLiteral)(nil)},
+			&ExpressionStatement{Expression: &IfExpression{
+				Condition:   &IntegerLiteral{Value: 1},
+				Consequence: &BlockStatement{Statements: []Statement{(*ExpressionStatement)(nil)}},
+				Alternative: (*BlockStatement)(nil),
+			}},
+		},
+	}
+	modify := func(node Node) Node {
+		if isNil(node) {
+			t.Errorf("modifier called with nil %T", node)
+		}
+		if integer, ok := node.(*IntegerLiteral); ok {
+			return &IntegerLiteral{Value: integer.Value + 1}
+		}
+		return node
+	}
+	Modify(program, modify)
+	if program.Statements[0] != (*LetStatement)(nil) {
+		t.Errorf("nil statement replaced. got=%#v", program.Statements[0])
+	}
+	cond := program.Statements[2].(*ExpressionStatement).Expression.(*IfExpression).Condition
+	if cond.(*IntegerLiteral).Value != 2 {
+		t.Errorf("condition not modified. got=%s", cond.String())
+	}
+}
+
+func TestModifyFunctionParameters(t *testing.T) {
+	fn := &FunctionLiteral{
+		Parameters: []*Identifier{{Value: "x"}, {Value: "y"}},
+		Body: &BlockStatement{
+			Statements: []Statement{
+				&ExpressionStatement{Expression: &Identifier{Value: "x"}},
+			},
+		},
+	}
+	rename := func(node Node) Node {
+		if ident, ok := node.(*Identifier); ok && ident.Value == "x" {
+			return &Identifier{Value: "z"}
+		}
+		return node
+	}
+	Modify(fn, rename)
+	if fn.Parameters[0].Value != "z" || fn.Parameters[1].Value != "y" {
+		t.Errorf("parameters not renamed. got=%s, %s",
+			fn.Parameters[0].Value, fn.Parameters[1].Value)
+	}
+	if fn.Body.String() != "z" {
+		t.Errorf("body not renamed. got=%q", fn.Body.String())
+	}
+}
+
+func TestModifyComments(t *testing.T) {
+	program := &Program{
+		Comments: []*CommentGroup{
+			{List: []*Comment{
+				{Token: token.Token{Type: token.COMMENT, Literal: "// todo"}},
+				{Token: token.Token{Type: token.COMMENT, Literal: "// keep"}},
+			}},
+		},
+	}
+	upper := func(node Node) Node {
+		if c, ok := node.(*Comment); ok && c.Token.Literal == "// todo" {
+			return &Comment{Token: token.Token{Type: token.COMMENT, Literal: "// TODO"}}
+		}
+		return node
+	}
+	Modify(program, upper)
+	if got := program.Comments[0].String(); got != "// TODO\n// keep" {
+		t.Errorf("comments not modified. got=%q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/lindeneg/monkey/token"
+)
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// Fprint writes an indented tree representation of node to w.
+// Every node is printed with its kind, the literal of its token
+// and the position of said token, followed by its fields.
+func Fprint(w io.Writer, node Node) error {
+	p := &treePrinter{w: w}
+	p.node(reflect.ValueOf(node), 0)
+	return p.err
+}
+
+type treePrinter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *treePrinter) printf(depth int, format string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, strings.Repeat("  ", depth)+format+"\n", a...)
+}
+
+// node prints v, which is expected to hold a Node, at the given depth
+func (p *treePrinter) node(v reflect.Value, depth int) {
+	v = indirectInterface(v)
+	if !v.IsValid() || v.IsNil() {
+		p.printf(depth, "nil")
+		return
+	}
+	s := v.Elem()
+	if f := s.FieldByName("Token"); f.IsValid() {
+		tok := f.Interface().(token.Token)
+		p.printf(depth, "%s %q (%d:%d)", s.Type().Name(), tok.Literal, tok.Line, tok.Col)
+	} else {
+		p.printf(depth, "%s", s.Type().Name())
+	}
+	for i := 0; i < s.NumField(); i++ {
+		field := s.Type().Field(i)
+		if field.Type == tokenType || !field.IsExported() {
+			continue
+		}
+		p.field(field.Name, s.Field(i), depth+1)
+	}
+}
+
+// field prints a named struct field of a node
+func (p *treePrinter) field(name string, v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Slice:
+		p.printf(depth, "%s: [%d]", name, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if v.Index(i).Kind() == reflect.Struct {
+				p.field(v.Index(i).Type().Name(), v.Index(i), depth+1)
+			} else {
+				p.node(v.Index(i), depth+1)
+			}
+		}
+	case reflect.Struct:
+		p.printf(depth, "%s:", name)
+		for i := 0; i < v.NumField(); i++ {
+			p.field(v.Type().Field(i).Name, v.Field(i), depth+1)
+		}
+	case reflect.Interface, reflect.Pointer:
+		p.printf(depth, "%s:", name)
+		p.node(v, depth+1)
+	default:
+		p.printf(depth, "%s: %#v", name, v.Interface())
+	}
+}
+
+// indirectInterface unwraps an interface value to the
+// concrete value it holds, if any
+func indirectInterface(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		return v.Elem()
+	}
+	return v
+}
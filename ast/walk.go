@@ -0,0 +1,142 @@
+package ast
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor
+// w for each of the non-nil children of node, followed by a call of
+// w.Visit(nil). The comments of a Program are visited after its
+// statements.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+		for _, g := range n.Comments {
+			walkIfNotNil(v, g)
+		}
+	case *CommentGroup:
+		for _, c := range n.List {
+			walkIfNotNil(v, c)
+		}
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+	case *LetStatement:
+		walkIfNotNil(v, n.Name)
+		walkIfNotNil(v, n.Pattern)
+		walkIfNotNil(v, n.Value)
+	case *ReturnStatement:
+		walkIfNotNil(v, n.ReturnValue)
+	case *ThrowStatement:
+		walkIfNotNil(v, n.Value)
+	case *ExpressionStatement:
+		walkIfNotNil(v, n.Expression)
+	case *PrefixExpression:
+		walkIfNotNil(v, n.Right)
+	case *InfixExpression:
+		walkIfNotNil(v, n.Left)
+		walkIfNotNil(v, n.Right)
+	case *IndexExpression:
+		walkIfNotNil(v, n.Left)
+		walkIfNotNil(v, n.Index)
+	case *ImportExpression:
+		walkIfNotNil(v, n.Path)
+	case *SliceExpression:
+		walkIfNotNil(v, n.Left)
+		walkIfNotNil(v, n.Start)
+		walkIfNotNil(v, n.End)
+		walkIfNotNil(v, n.Step)
+	case *IfExpression:
+		walkIfNotNil(v, n.Condition)
+		walkIfNotNil(v, n.Consequence)
+		walkIfNotNil(v, n.Alternative)
+	case *TryExpression:
+		walkIfNotNil(v, n.Block)
+		walkIfNotNil(v, n.Param)
+		walkIfNotNil(v, n.Catch)
+		walkIfNotNil(v, n.Finally)
+	case *MatchExpression:
+		walkIfNotNil(v, n.Subject)
+		for _, arm := range n.Arms {
+			walkIfNotNil(v, arm.Pattern)
+			walkIfNotNil(v, arm.Guard)
+			walkIfNotNil(v, arm.Body)
+		}
+	case *ArrayPattern:
+		walkExpressions(v, n.Elements)
+		walkIfNotNil(v, n.Rest)
+	case *DefaultPattern:
+		walkIfNotNil(v, n.Pattern)
+		walkIfNotNil(v, n.Default)
+	case *HashPattern:
+		for _, pair := range n.Pairs {
+			walkIfNotNil(v, pair.Key)
+			walkIfNotNil(v, pair.Value)
+		}
+	case *FunctionLiteral:
+		for _, p := range n.Parameters {
+			walkIfNotNil(v, p)
+		}
+		walkIfNotNil(v, n.Body)
+	case *CallExpression:
+		walkIfNotNil(v, n.Function)
+		walkExpressions(v, n.Arguments)
+	case *ArrayLiteral:
+		walkExpressions(v, n.Elements)
+	case *HashLiteral:
+		for _, pair := range n.Pairs {
+			walkIfNotNil(v, pair.Key)
+			walkIfNotNil(v, pair.Value)
+		}
+	case *Identifier, *IntegerLiteral, *StringLiteral, *Boolean, *Comment:
+		// nothing to do
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, list []Statement) {
+	for _, s := range list {
+		walkIfNotNil(v, s)
+	}
+}
+
+func walkExpressions(v Visitor, list []Expression) {
+	for _, e := range list {
+		walkIfNotNil(v, e)
+	}
+}
+
+// walkIfNotNil guards against typed nil pointers stored in
+// interfaces, which the parser produces on syntax errors
+func walkIfNotNil(v Visitor, node Node) {
+	if !isNil(node) {
+		Walk(v, node)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,131 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/lindeneg/monkey/token"
+)
+
+func TestInspect(t *testing.T) {
+	one := func() Expression { return &IntegerLiteral{Value: 1} }
+	ident := func(name string) *Identifier { return &Identifier{Value: name} }
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Name: ident("f"),
+				Value: &FunctionLiteral{
+					Parameters: []*Identifier{ident("x"), ident("y")},
+					Body: &BlockStatement{
+						Statements: []Statement{
+							&ReturnStatement{ReturnValue: &InfixExpression{
+								Left: ident("x"), Operator: "+", Right: ident("y"),
+							}},
+						},
+					},
+				},
+			},
+			&ExpressionStatement{Expression: &HashLiteral{
+				Pairs: []HashPair{{Key: one(), Value: ident("v")}},
+			}},
+			&ExpressionStatement{Expression: &IfExpression{
+				Condition:   &Boolean{Value: true},
+				Consequence: &BlockStatement{},
+			}},
+		},
+	}
+
+	var visited []string
+	Inspect(program, func(n Node) bool {
+		if n != nil {
+			visited = append(visited, fmt.Sprintf("%T", n))
+		}
+		return true
+	})
+	expected := []string{
+		"*ast.Program",
+		"*ast.LetStatement",
+		"*ast.Identifier",
+		"*ast.FunctionLiteral",
+		"*ast.Identifier",
+		"*ast.Identifier",
+		"*ast.BlockStatement",
+		"*ast.ReturnStatement",
+		"*ast.InfixExpression",
+		"*ast.Identifier",
+		"*ast.Identifier",
+		"*ast.ExpressionStatement",
+		"*ast.HashLiteral",
+		"*ast.IntegerLiteral",
+		"*ast.Identifier",
+		"*ast.ExpressionStatement",
+		"*ast.IfExpression",
+		"*ast.Boolean",
+		"*ast.BlockStatement",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("wrong visiting order.\nexpected=%v\ngot=%v", expected, visited)
+	}
+}
+
+func TestInspectComments(t *testing.T) {
+	comment := func(text string) *Comment {
+		return &Comment{Token: token.Token{Type: token.COMMENT, Literal: text}}
+	}
+	program := &Program{
+		Statements: []Statement{
+			&ExpressionStatement{Expression: &Identifier{Value: "x"}},
+		},
+		Comments: []*CommentGroup{
+			{List: []*Comment{comment("// a"), comment("// b")}},
+			{List: []*Comment{comment("// c")}},
+		},
+	}
+
+	var visited []string
+	Inspect(program, func(n Node) bool {
+		if n != nil {
+			visited = append(visited, fmt.Sprintf("%T %s", n, n.TokenLiteral()))
+		}
+		return true
+	})
+	expected := []string{
+		"*ast.Program ",
+		"*ast.ExpressionStatement ",
+		"*ast.Identifier ",
+		"*ast.CommentGroup // a",
+		"*ast.Comment // a",
+		"*ast.Comment // b",
+		"*ast.CommentGroup // c",
+		"*ast.Comment // c",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("wrong visiting order.\nexpected=%q\ngot=%q", expected, visited)
+	}
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&ExpressionStatement{Expression: &FunctionLiteral{
+				Parameters: []*Identifier{{Value: "x"}},
+				Body:       &BlockStatement{},
+			}},
+			&ExpressionStatement{Expression: &Identifier{Value: "y"}},
+		},
+	}
+
+	var idents []string
+	Inspect(program, func(n Node) bool {
+		if ident, ok := n.(*Identifier); ok {
+			idents = append(idents, ident.Value)
+		}
+		_, isFn := n.(*FunctionLiteral)
+		return !isFn
+	})
+	if !reflect.DeepEqual(idents, []string{"y"}) {
+		t.Errorf("expected only identifier y to be visited, got=%v", idents)
+	}
+}
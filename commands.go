@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lindeneg/monkey/ast"
+	"github.com/lindeneg/monkey/evaluator"
+	"github.com/lindeneg/monkey/format"
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/object"
+	"github.com/lindeneg/monkey/parser"
+	"github.com/lindeneg/monkey/repl"
+	"github.com/lindeneg/monkey/token"
+)
+
+const usage = `usage:
+	monkey                          start the REPL
+	monkey <file>                   evaluate file
+	monkey run <file>               evaluate file, even if named like a command
+	monkey tokens <file>            print the tokens of file
+	monkey ast [--json] <file>      print the syntax tree of file
+	monkey fmt [-w] [-d] [files...] format files, or standard input
+	monkey help                     print this text`
+
+// commands maps subcommand names to their implementation.
+// Anything not found here is treated as a file to evaluate,
+// a file named like a command has to be evaluated with run.
+var commands = map[string]func(args []string) error{
+	"run":    runFile,
+	"tokens": runTokens,
+	"ast":    runAST,
+	"fmt":    runFmt,
+	"help":   runHelp,
+}
+
+// monkey help
+func runHelp(args []string) error {
+	fmt.Println(usage)
+	return nil
+}
+
+// monkey run <file>
+func runFile(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: monkey run <file>")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	env := object.NewFileEnvironment(args[0], nil)
+	p := parser.New(lexer.NewLexer(string(data)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		repl.PrintParserErrors(os.Stdout, p.Errors())
+	}
+	result := evaluator.Eval(program, env)
+	if err, ok := result.(*object.Error); ok {
+		if err.File != "" && err.File != args[0] {
+			fmt.Printf("%s (line %d in %s)\n", err.Inspect(), err.Line, err.File)
+		} else {
+			fmt.Printf("%s (line %d)\n", err.Inspect(), err.Line)
+		}
+		for _, frame := range err.Stack {
+			fmt.Printf("\tin %s\n", frame)
+		}
+	}
+	return nil
+}
+
+// monkey tokens <file>
+func runTokens(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: monkey tokens <file>")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	l := lexer.NewLexer(string(data))
+	for {
+		tok := l.NextToken()
+		fmt.Printf("%d:%d\t%s\t%q\n", tok.Line, tok.Col, tok.Type, tok.Literal)
+		if tok.Type == token.EOF {
+			return nil
+		}
+	}
+}
+
+// monkey ast [--json] <file>
+func runAST(args []string) error {
+	fs := flag.NewFlagSet("ast", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the tree as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: monkey ast [--json] <file>")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	p := parser.New(lexer.NewLexer(string(data)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		repl.PrintParserErrors(os.Stderr, p.Errors())
+		return errors.New("failed to parse " + fs.Arg(0))
+	}
+	if *asJSON {
+		data, err := ast.MarshalIndent(program, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	return ast.Fprint(os.Stdout, program)
+}
+
+// monkey fmt [-w] [-d] [files...]
+func runFmt(args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := fs.Bool("w", false, "write result to (source) file instead of stdout")
+	diff := fs.Bool("d", false, "display diffs instead of rewriting files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		if *write {
+			return errors.New("cannot use -w with standard input")
+		}
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		return formatFile(os.Stdout, "<standard input>", src, false, *diff)
+	}
+	for _, name := range fs.Args() {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := formatFile(os.Stdout, name, src, *write, *diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatFile formats src, the content of the file name, writing
+// the result or its diff to w, or the result back to the file
+func formatFile(w io.Writer, name string, src []byte, write, diff bool) error {
+	res, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if diff && !bytes.Equal(src, res) {
+		writeDiff(w, name, string(src), string(res))
+	}
+	if write {
+		if bytes.Equal(src, res) {
+			return nil
+		}
+		return os.WriteFile(name, res, 0644)
+	}
+	if !diff {
+		_, err = w.Write(res)
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFormatFileDiff(t *testing.T) {
+	src := "let x=1;\nlet y = 2;\nputs(x+y);\n"
+	expected := "--- f.monkey.orig\n+++ f.monkey\n@@ -1,3 +1,3 @@\n" +
+		"-let x=1;\n+let x = 1;\n let y = 2;\n-puts(x+y);\n+puts(x + y);\n"
+	var out bytes.Buffer
+	if err := formatFile(&out, "f.monkey", []byte(src), false, true); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Fatalf("expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestFormatFileUnchanged(t *testing.T) {
+	src := "let x = 1;\nputs(x);\n"
+	var out bytes.Buffer
+	if err := formatFile(&out, "f.monkey", []byte(src), false, true); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no diff, got=%q", out.String())
+	}
+	if err := formatFile(&out, "f.monkey", []byte(src), false, false); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != src {
+		t.Fatalf("expected=%q, got=%q", src, out.String())
+	}
+}
+
+func TestRunFmtWrite(t *testing.T) {
+	dir := t.TempDir()
+	ugly := filepath.Join(dir, "ugly.monkey")
+	pretty := filepath.Join(dir, "pretty.monkey")
+	if err := os.WriteFile(ugly, []byte("let x=1;puts( x )"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pretty, []byte("let x = 1;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a formatted file must not be rewritten
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(pretty, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := runFmt([]string{"-w", ugly, pretty}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(pretty); err != nil {
+		t.Fatal(err)
+	} else if !info.ModTime().Equal(past) {
+		t.Errorf("%s: rewritten although formatted", pretty)
+	}
+	for name, expected := range map[string]string{
+		ugly:   "let x = 1;\nputs(x);\n",
+		pretty: "let x = 1;\n",
+	} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("%s: expected=%q, got=%q", name, expected, string(data))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around a change
+const contextLines = 3
+
+// maxDiffCells bounds the size of the table diffLines builds, larger
+// inputs are diffed as a whole, by removing a and adding b
+const maxDiffCells = 1 << 24
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// diffLines computes a line based diff of a and b from
+// their longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		ops := make([]diffOp, 0, len(a)+len(b))
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeDiff writes a unified diff between the old and new
+// content of the file name to w
+func writeDiff(w io.Writer, name, old, new string) {
+	ops := diffLines(splitLines(old), splitLines(new))
+	fmt.Fprintf(w, "--- %s.orig\n+++ %s\n", name, name)
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		// extend the hunk until contextLines*2 unchanged lines are seen
+		from := start - contextLines
+		if from < 0 {
+			from = 0
+		}
+		to := start
+		for unchanged := 0; to < len(ops) && unchanged <= contextLines*2; to++ {
+			if ops[to].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		for to > start && ops[to-1].kind == ' ' && trailingContext(ops[start:to]) > contextLines {
+			to--
+		}
+		writeHunk(w, ops, from, to)
+		start = to
+	}
+}
+
+// trailingContext counts the unchanged operations at the end of ops
+func trailingContext(ops []diffOp) int {
+	n := 0
+	for i := len(ops) - 1; i >= 0 && ops[i].kind == ' '; i-- {
+		n++
+	}
+	return n
+}
+
+func writeHunk(w io.Writer, ops []diffOp, from, to int) {
+	oldStart, newStart := 1, 1
+	for _, op := range ops[:from] {
+		if op.kind != '+' {
+			oldStart++
+		}
+		if op.kind != '-' {
+			newStart++
+		}
+	}
+	oldLen, newLen := 0, 0
+	for _, op := range ops[from:to] {
+		if op.kind != '+' {
+			oldLen++
+		}
+		if op.kind != '-' {
+			newLen++
+		}
+	}
+	fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", oldStart, oldLen, newStart, newLen)
+	for _, op := range ops[from:to] {
+		line := op.line
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n\\ No newline at end of file\n"
+		}
+		fmt.Fprintf(w, "%c%s", op.kind, line)
+	}
+}
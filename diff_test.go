@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteDiff(t *testing.T) {
+	tests := []struct {
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			"--- f.orig\n+++ f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"a\n",
+			"a\nb\n",
+			"--- f.orig\n+++ f\n@@ -1,1 +1,2 @@\n a\n+b\n",
+		},
+		{
+			"a",
+			"a\n",
+			"--- f.orig\n+++ f\n@@ -1,1 +1,1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"0\n2\n3\n4\n5\n6\n7\n8\n9\n0\n",
+			"--- f.orig\n+++ f\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+0\n",
+		},
+		{"a\n", "a\n", "--- f.orig\n+++ f\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		writeDiff(&out, "f", tt.old, tt.new)
+		if out.String() != tt.expected {
+			t.Errorf("diff of %q and %q: expected=%q, got=%q",
+				tt.old, tt.new, tt.expected, out.String())
+		}
+	}
+}
+
+func TestDiffLinesLargeInput(t *testing.T) {
+	a := strings.Split(strings.Repeat("a\n", 5000), "\n")
+	b := strings.Split(strings.Repeat("b\n", 5000), "\n")
+	ops := diffLines(a, b)
+	if len(ops) != len(a)+len(b) {
+		t.Fatalf("expected %d operations, got=%d", len(a)+len(b), len(ops))
+	}
+	for i, op := range ops {
+		want := byte('-')
+		if i >= len(a) {
+			want = '+'
+		}
+		if op.kind != want {
+			t.Fatalf("operation %d: expected %c, got=%c", i, want, op.kind)
+		}
+	}
+}
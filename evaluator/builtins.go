@@ -3,10 +3,19 @@ package evaluator
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/lindeneg/monkey/object"
 )
 
+// registerBuiltins adds a set of builtins, files
+// defining related builtins call it from their init
+func registerBuiltins(set map[string]*object.Builtin) {
+	for name, builtin := range set {
+		builtins[name] = builtin
+	}
+}
+
 var builtins = map[string]*object.Builtin{
 	"len": {
 		Fn: func(args ...object.Object) object.Object {
@@ -18,7 +27,9 @@ var builtins = map[string]*object.Builtin{
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(arg.Len())}
 			default:
 				return newError("argument to `len` not supported, got %s",
 					args[0].Type())
@@ -43,14 +54,19 @@ var builtins = map[string]*object.Builtin{
 				return newError("wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `first` must be ARRAY, got %s",
+			switch arg := args[0].(type) {
+			case *object.Array:
+				if len(arg.Elements) > 0 {
+					return arg.Elements[0]
+				}
+			case *object.String:
+				if r, size := utf8.DecodeRuneInString(arg.Value); size > 0 {
+					return &object.String{Value: string(r)}
+				}
+			default:
+				return newError("argument to `first` must be ARRAY or STRING, got %s",
 					args[0].Type())
 			}
-			arr := args[0].(*object.Array)
-			if len(arr.Elements) > 0 {
-				return arr.Elements[0]
-			}
 			return NULL
 		},
 	},
@@ -60,15 +76,20 @@ var builtins = map[string]*object.Builtin{
 				return newError("wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `last` must be ARRAY, got %s",
+			switch arg := args[0].(type) {
+			case *object.Array:
+				length := len(arg.Elements)
+				if length > 0 {
+					return arg.Elements[length-1]
+				}
+			case *object.String:
+				if r, size := utf8.DecodeLastRuneInString(arg.Value); size > 0 {
+					return &object.String{Value: string(r)}
+				}
+			default:
+				return newError("argument to `last` must be ARRAY or STRING, got %s",
 					args[0].Type())
 			}
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
-			if length > 0 {
-				return arr.Elements[length-1]
-			}
 			return NULL
 		},
 	},
@@ -78,17 +99,22 @@ var builtins = map[string]*object.Builtin{
 				return newError("wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `rest` must be ARRAY, got %s",
+			switch arg := args[0].(type) {
+			case *object.Array:
+				length := len(arg.Elements)
+				if length > 0 {
+					newElements := make([]object.Object, length-1, length-1)
+					copy(newElements, arg.Elements[1:length])
+					return &object.Array{Elements: newElements}
+				}
+			case *object.String:
+				if _, size := utf8.DecodeRuneInString(arg.Value); size > 0 {
+					return &object.String{Value: arg.Value[size:]}
+				}
+			default:
+				return newError("argument to `rest` must be ARRAY or STRING, got %s",
 					args[0].Type())
 			}
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
-			if length > 0 {
-				newElements := make([]object.Object, length-1, length-1)
-				copy(newElements, arr.Elements[1:length])
-				return &object.Array{Elements: newElements}
-			}
 			return NULL
 		},
 	},
@@ -0,0 +1,382 @@
+package evaluator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lindeneg/monkey/object"
+)
+
+func init() {
+	registerBuiltins(arrayBuiltins)
+}
+
+// maxRangeLength is the largest number of elements `range` returns
+const maxRangeLength = 1 << 26
+
+// Builtins operating on arrays. None of them modify their
+// argument, those returning an array return a new one.
+var arrayBuiltins = map[string]*object.Builtin{
+	"map": {
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndFunction("map", args)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, len(arr.Elements))
+			for i, el := range arr.Elements {
+				result := ctx.Call(fn, el)
+				if isError(result) {
+					return result
+				}
+				elements[i] = result
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"filter": {
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndFunction("filter", args)
+			if err != nil {
+				return err
+			}
+			elements := []object.Object{}
+			for _, el := range arr.Elements {
+				result := ctx.Call(fn, el)
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					elements = append(elements, el)
+				}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"reduce": {
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3",
+					len(args))
+			}
+			arr, fn, err := arrayAndFunction("reduce", []object.Object{args[0], args[2]})
+			if err != nil {
+				return err
+			}
+			result := args[1]
+			for _, el := range arr.Elements {
+				result = ctx.Call(fn, result, el)
+				if isError(result) {
+					return result
+				}
+			}
+			return result
+		},
+	},
+	"find": {
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndFunction("find", args)
+			if err != nil {
+				return err
+			}
+			for _, el := range arr.Elements {
+				result := ctx.Call(fn, el)
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return el
+				}
+			}
+			return NULL
+		},
+	},
+	"any": {
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndFunction("any", args)
+			if err != nil {
+				return err
+			}
+			for _, el := range arr.Elements {
+				result := ctx.Call(fn, el)
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+	},
+	"all": {
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndFunction("all", args)
+			if err != nil {
+				return err
+			}
+			for _, el := range arr.Elements {
+				result := ctx.Call(fn, el)
+				if isError(result) {
+					return result
+				}
+				if !isTruthy(result) {
+					return FALSE
+				}
+			}
+			return TRUE
+		},
+	},
+	// sort orders integers and strings ascending. If a comparator
+	// is given it is called with two elements and must report
+	// whether the first one belongs before the second.
+	"sort": {
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2",
+					len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `sort` must be ARRAY, got %s",
+					args[0].Type())
+			}
+			var less func(a, b object.Object) (bool, object.Object)
+			if len(args) == 2 {
+				if !isCallable(args[1]) {
+					return newError("argument to `sort` must be FUNCTION, got %s",
+						args[1].Type())
+				}
+				less = func(a, b object.Object) (bool, object.Object) {
+					result := ctx.Call(args[1], a, b)
+					if isError(result) {
+						return false, result
+					}
+					return isTruthy(result), nil
+				}
+			} else {
+				less = func(a, b object.Object) (bool, object.Object) {
+					return naturalLess(a, b)
+				}
+			}
+			elements := make([]object.Object, len(arr.Elements))
+			copy(elements, arr.Elements)
+			var sortErr object.Object
+			sort.SliceStable(elements, func(i, j int) bool {
+				if sortErr != nil {
+					return false
+				}
+				result, err := less(elements[i], elements[j])
+				if err != nil {
+					sortErr = err
+				}
+				return result
+			})
+			if sortErr != nil {
+				return sortErr
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"reverse": {
+		Fn: func(args ...object.Object) object.Object {
+			arr, err := arrayArgument("reverse", 1, args)
+			if err != nil {
+				return err
+			}
+			length := len(arr.Elements)
+			elements := make([]object.Object, length)
+			for i, el := range arr.Elements {
+				elements[length-1-i] = el
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	// zip pairs up the elements of its arguments,
+	// stopping at the end of the shortest one
+	"zip": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 2 {
+				return newError("wrong number of arguments. got=%d, want at least 2",
+					len(args))
+			}
+			arrays := make([]*object.Array, len(args))
+			length := -1
+			for i, arg := range args {
+				arr, ok := arg.(*object.Array)
+				if !ok {
+					return newError("argument to `zip` must be ARRAY, got %s",
+						arg.Type())
+				}
+				if length < 0 || len(arr.Elements) < length {
+					length = len(arr.Elements)
+				}
+				arrays[i] = arr
+			}
+			elements := make([]object.Object, length)
+			for i := range elements {
+				tuple := make([]object.Object, len(arrays))
+				for j, arr := range arrays {
+					tuple[j] = arr.Elements[i]
+				}
+				elements[i] = &object.Array{Elements: tuple}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	// flatten removes one level of nesting
+	"flatten": {
+		Fn: func(args ...object.Object) object.Object {
+			arr, err := arrayArgument("flatten", 1, args)
+			if err != nil {
+				return err
+			}
+			elements := []object.Object{}
+			for _, el := range arr.Elements {
+				if inner, ok := el.(*object.Array); ok {
+					elements = append(elements, inner.Elements...)
+				} else {
+					elements = append(elements, el)
+				}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	// range(end), range(start, end) or range(start, end, step)
+	// of at most maxRangeLength elements
+	"range": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1 to 3",
+					len(args))
+			}
+			bounds := make([]int64, len(args))
+			for i, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("argument to `range` must be INTEGER, got %s",
+						arg.Type())
+				}
+				bounds[i] = integer.Value
+			}
+			start, end, step := int64(0), bounds[0], int64(1)
+			if len(bounds) > 1 {
+				start, end = bounds[0], bounds[1]
+			}
+			if len(bounds) > 2 {
+				step = bounds[2]
+			}
+			if step == 0 {
+				return newError("range step cannot be zero")
+			}
+			n := rangeLength(start, end, step)
+			if n > maxRangeLength {
+				return newError("range too long: %d elements", n)
+			}
+			elements := make([]object.Object, n)
+			for k := range elements {
+				elements[k] = &object.Integer{Value: start + int64(k)*step}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	// join concatenates the elements of an array, strings as
+	// they are and anything else as it is inspected
+	"join": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2",
+					len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `join` must be ARRAY, got %s",
+					args[0].Type())
+			}
+			sep := ""
+			if len(args) == 2 {
+				str, ok := args[1].(*object.String)
+				if !ok {
+					return newError("argument to `join` must be STRING, got %s",
+						args[1].Type())
+				}
+				sep = str.Value
+			}
+			parts := make([]string, len(arr.Elements))
+			for i, el := range arr.Elements {
+				if str, ok := el.(*object.String); ok {
+					parts[i] = str.Value
+				} else {
+					parts[i] = el.Inspect()
+				}
+			}
+			return &object.String{Value: strings.Join(parts, sep)}
+		},
+	},
+}
+
+// rangeLength returns the number of integers from start to end,
+// exclusive, by step. The distance is computed unsigned, as it
+// may not fit in an int64, and no integer past end is computed.
+func rangeLength(start, end, step int64) uint64 {
+	switch {
+	case step > 0 && start < end:
+		return (uint64(end)-uint64(start)-1)/uint64(step) + 1
+	case step < 0 && start > end:
+		return (uint64(start)-uint64(end)-1)/(-uint64(step)) + 1
+	}
+	return 0
+}
+
+// arrayArgument checks that args holds want arguments, the first
+// of which is an array, and returns said array or an error
+func arrayArgument(name string, want int, args []object.Object) (*object.Array, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args), want)
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, newError("argument to `%s` must be ARRAY, got %s",
+			name, args[0].Type())
+	}
+	return arr, nil
+}
+
+// arrayAndFunction checks that args holds an array
+// followed by a function and returns them or an error
+func arrayAndFunction(name string, args []object.Object) (*object.Array, object.Object, *object.Error) {
+	arr, err := arrayArgument(name, 2, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isCallable(args[1]) {
+		return nil, nil, newError("argument to `%s` must be FUNCTION, got %s",
+			name, args[1].Type())
+	}
+	return arr, args[1], nil
+}
+
+func isCallable(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Function, *object.Builtin:
+		return true
+	default:
+		return false
+	}
+}
+
+// naturalLess orders two integers or two strings
+func naturalLess(a, b object.Object) (bool, object.Object) {
+	switch a := a.(type) {
+	case *object.Integer:
+		if b, ok := b.(*object.Integer); ok {
+			return a.Value < b.Value, nil
+		}
+	case *object.String:
+		if b, ok := b.(*object.String); ok {
+			return a.Value < b.Value, nil
+		}
+	}
+	return false, newError("cannot compare %s and %s", a.Type(), b.Type())
+}
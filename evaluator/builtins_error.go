@@ -0,0 +1,51 @@
+package evaluator
+
+import (
+	"github.com/lindeneg/monkey/object"
+)
+
+func init() {
+	registerBuiltins(errorBuiltins)
+}
+
+// Builtins for errors as values. Unlike the errors raised by
+// failing operations and throw, these do not abort evaluation.
+var errorBuiltins = map[string]*object.Builtin{
+	"error": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("error", 1, args)
+			if err != nil {
+				return err
+			}
+			return &object.ErrorValue{Message: strs[0]}
+		},
+	},
+	"is_error": typePredicate(object.ERROR_VALUE_OBJ),
+	"error_message": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+			errValue, ok := args[0].(*object.ErrorValue)
+			if !ok {
+				return newError("argument to `error_message` must be ERROR_VALUE, got %s",
+					args[0].Type())
+			}
+			return &object.String{Value: errValue.Message}
+		},
+	},
+}
+
+// evalErrorValueIndexExpression gives access to the
+// message of an error value as err["message"]
+func evalErrorValueIndexExpression(errValue, index object.Object) object.Object {
+	key, ok := index.(*object.String)
+	if !ok {
+		return newError("error value member must be STRING, got %s", index.Type())
+	}
+	if key.Value != "message" {
+		return newError("error value has no member %q", key.Value)
+	}
+	return &object.String{Value: errValue.(*object.ErrorValue).Message}
+}
@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"github.com/lindeneg/monkey/object"
+)
+
+func init() {
+	registerBuiltins(hashBuiltins)
+}
+
+// Builtins operating on hashes. Like push for arrays,
+// those that change a hash return a new one and leave
+// their argument untouched.
+var hashBuiltins = map[string]*object.Builtin{
+	"keys": {
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := hashArgument("keys", 1, args)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, 0, hash.Len())
+			for _, pair := range hash.Pairs() {
+				elements = append(elements, pair.Key)
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"values": {
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := hashArgument("values", 1, args)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, 0, hash.Len())
+			for _, pair := range hash.Pairs() {
+				elements = append(elements, pair.Value)
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"entries": {
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := hashArgument("entries", 1, args)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, 0, hash.Len())
+			for _, pair := range hash.Pairs() {
+				elements = append(elements, &object.Array{
+					Elements: []object.Object{pair.Key, pair.Value},
+				})
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"has": {
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := hashArgument("has", 2, args)
+			if err != nil {
+				return err
+			}
+			key, ok := object.HashableKey(args[1])
+			if !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+			_, found := hash.Get(key)
+			return nativeBoolToBooleanObject(found)
+		},
+	},
+	"delete": {
+		Fn: func(args ...object.Object) object.Object {
+			hash, err := hashArgument("delete", 2, args)
+			if err != nil {
+				return err
+			}
+			key, ok := object.HashableKey(args[1])
+			if !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+			result := hash.Copy()
+			result.Delete(key)
+			return result
+		},
+	},
+	"merge": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 2 {
+				return newError("wrong number of arguments. got=%d, want at least 2",
+					len(args))
+			}
+			result := object.NewHash()
+			for _, arg := range args {
+				hash, ok := arg.(*object.Hash)
+				if !ok {
+					return newError("argument to `merge` must be HASH, got %s",
+						arg.Type())
+				}
+				for _, pair := range hash.Pairs() {
+					result.Set(pair.Key.(object.Hashable), pair.Value)
+				}
+			}
+			return result
+		},
+	},
+}
+
+// hashArgument checks that args holds want arguments, the first
+// of which is a hash, and returns said hash or an error
+func hashArgument(name string, want int, args []object.Object) (*object.Hash, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args), want)
+	}
+	hash, ok := args[0].(*object.Hash)
+	if !ok {
+		return nil, newError("argument to `%s` must be HASH, got %s",
+			name, args[0].Type())
+	}
+	return hash, nil
+}
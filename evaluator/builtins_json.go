@@ -0,0 +1,219 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lindeneg/monkey/object"
+)
+
+func init() {
+	registerBuiltins(jsonBuiltins)
+}
+
+// maxIndent is the largest number of spaces json_encode indents by
+const maxIndent = 10
+
+// Builtins converting between Monkey values and JSON. Hashes keep
+// the order of their keys both ways. Since Monkey only has integers,
+// JSON numbers with a fraction or an exponent cannot be decoded.
+var jsonBuiltins = map[string]*object.Builtin{
+	// json_encode(value) or json_encode(value, indent) where
+	// indent is a string or a number of spaces, at most maxIndent
+	"json_encode": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2",
+					len(args))
+			}
+			var buf bytes.Buffer
+			if err := encodeJSON(&buf, args[0]); err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				return &object.String{Value: buf.String()}
+			}
+			var indent string
+			switch arg := args[1].(type) {
+			case *object.String:
+				indent = arg.Value
+			case *object.Integer:
+				if arg.Value < 0 {
+					return newError("negative indent: %d", arg.Value)
+				}
+				if arg.Value > maxIndent {
+					return newError("indent too large: %d, max is %d", arg.Value, maxIndent)
+				}
+				indent = strings.Repeat(" ", int(arg.Value))
+			default:
+				return newError("argument to `json_encode` must be STRING or INTEGER, got %s",
+					args[1].Type())
+			}
+			var out bytes.Buffer
+			if err := json.Indent(&out, buf.Bytes(), "", indent); err != nil {
+				return newError("json_encode: %s", err)
+			}
+			return &object.String{Value: out.String()}
+		},
+	},
+	"json_decode": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("json_decode", 1, args)
+			if err != nil {
+				return err
+			}
+			return decodeJSON(strs[0])
+		},
+	},
+}
+
+// encodeJSON writes the compact JSON encoding of obj to buf.
+// Hash keys that are integers or booleans become strings.
+func encodeJSON(buf *bytes.Buffer, obj object.Object) *object.Error {
+	switch obj := obj.(type) {
+	case *object.Null:
+		buf.WriteString("null")
+	case *object.Integer, *object.Boolean:
+		buf.WriteString(obj.Inspect())
+	case *object.String:
+		writeJSONString(buf, obj.Value)
+	case *object.Array:
+		buf.WriteByte('[')
+		for i, el := range obj.Elements {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSON(buf, el); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case *object.Hash:
+		buf.WriteByte('{')
+		for i, pair := range obj.Pairs() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			switch key := pair.Key.(type) {
+			case *object.String:
+				writeJSONString(buf, key.Value)
+			case *object.Integer, *object.Boolean:
+				writeJSONString(buf, key.Inspect())
+			default:
+				return newError("cannot encode %s hash key as JSON", key.Type())
+			}
+			buf.WriteByte(':')
+			if err := encodeJSON(buf, pair.Value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return newError("cannot encode %s as JSON", obj.Type())
+	}
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	buf.Truncate(buf.Len() - 1) // Encode appends a newline
+}
+
+// decodeJSON parses src, which must hold a single JSON value. Syntax
+// errors are reported with the line and column they were found at.
+func decodeJSON(src string) object.Object {
+	dec := json.NewDecoder(strings.NewReader(src))
+	dec.UseNumber()
+	result, err := decodeJSONValue(dec)
+	if err == nil {
+		if _, err = dec.Token(); err == io.EOF {
+			return result
+		} else if err == nil {
+			err = errors.New("unexpected data after top-level value")
+		}
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	offset := dec.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+		// the offset is that of the byte after an invalid character
+		if strings.HasPrefix(syntaxErr.Error(), "invalid character") {
+			offset--
+		}
+	}
+	line, col := lineAndColumn(src, offset)
+	return newError("json_decode: %s at %d:%d", err, line, col)
+}
+
+func decodeJSONValue(dec *json.Decoder) (object.Object, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case nil:
+		return NULL, nil
+	case bool:
+		return nativeBoolToBooleanObject(tok), nil
+	case string:
+		return &object.String{Value: tok}, nil
+	case json.Number:
+		value, err := strconv.ParseInt(string(tok), 10, 64)
+		if err != nil {
+			return nil, errors.New("cannot represent " + string(tok) + " as INTEGER")
+		}
+		return &object.Integer{Value: value}, nil
+	case json.Delim:
+		if tok == '[' {
+			elements := []object.Object{}
+			for dec.More() {
+				el, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, el)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return &object.Array{Elements: elements}, nil
+		}
+		hash := object.NewHash()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(&object.String{Value: key.(string)}, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return hash, nil
+	}
+	return nil, errors.New("unexpected JSON token")
+}
+
+// lineAndColumn converts a byte offset in src to a 1-based line and column
+func lineAndColumn(src string, offset int64) (int, int) {
+	if offset > int64(len(src)) {
+		offset = int64(len(src))
+	}
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := len(before) - strings.LastIndexByte(before, '\n')
+	return line, col
+}
@@ -0,0 +1,292 @@
+package evaluator
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/lindeneg/monkey/object"
+)
+
+var (
+	// random is the source used by `random` and `rand_int`. A
+	// rand.Rand is not safe for concurrent use, hence randomMu.
+	random   = rand.New(rand.NewSource(time.Now().UnixNano()))
+	randomMu sync.Mutex
+)
+
+// SeedRandom makes the numbers returned by `random` and `rand_int`
+// depend on seed only, so that runs can be reproduced
+func SeedRandom(seed int64) {
+	randomMu.Lock()
+	defer randomMu.Unlock()
+	random = rand.New(rand.NewSource(seed))
+}
+
+func init() {
+	registerBuiltins(mathBuiltins)
+}
+
+// Builtins operating on integers. Monkey has no floating point
+// numbers, so sqrt, floor, ceil and the divisions produce integers
+// too. Results that do not fit in an integer are an error.
+var mathBuiltins = map[string]*object.Builtin{
+	"abs": {
+		Fn: func(args ...object.Object) object.Object {
+			ints, err := integerArguments("abs", 1, args)
+			if err != nil {
+				return err
+			}
+			if ints[0] == math.MinInt64 {
+				return newError("integer overflow: abs(%d)", ints[0])
+			}
+			if ints[0] < 0 {
+				return &object.Integer{Value: -ints[0]}
+			}
+			return args[0]
+		},
+	},
+	"min": {
+		Fn: func(args ...object.Object) object.Object {
+			return extremum("min", args, func(a, b int64) bool { return a < b })
+		},
+	},
+	"max": {
+		Fn: func(args ...object.Object) object.Object {
+			return extremum("max", args, func(a, b int64) bool { return a > b })
+		},
+	},
+	"pow": {
+		Fn: func(args ...object.Object) object.Object {
+			ints, err := integerArguments("pow", 2, args)
+			if err != nil {
+				return err
+			}
+			base, exp := ints[0], ints[1]
+			if exp < 0 {
+				return newError("negative exponent: %d", exp)
+			}
+			// once base is squared past the integer range the
+			// result would be too, unless it is already done
+			result, ok := int64(1), true
+			for exp > 0 && ok {
+				if exp&1 == 1 {
+					result, ok = multiply(result, base)
+				}
+				if exp >>= 1; exp > 0 && ok {
+					base, ok = multiply(base, base)
+				}
+			}
+			if !ok {
+				return newError("integer overflow: pow(%d, %d)", ints[0], ints[1])
+			}
+			return &object.Integer{Value: result}
+		},
+	},
+	// sqrt returns the integer square root, rounded down
+	"sqrt": {
+		Fn: func(args ...object.Object) object.Object {
+			ints, err := integerArguments("sqrt", 1, args)
+			if err != nil {
+				return err
+			}
+			n := ints[0]
+			if n < 0 {
+				return newError("square root of negative number: %d", n)
+			}
+			// Newton's method, starting above the root. Unsigned
+			// so that the first step cannot overflow.
+			x, u := uint64(n), uint64(n)
+			for y := (x + 1) / 2; y < x; y = (x + u/x) / 2 {
+				x = y
+			}
+			return &object.Integer{Value: int64(x)}
+		},
+	},
+	// floor(x) and ceil(x) are x, as integers are whole already
+	"floor": {
+		Fn: func(args ...object.Object) object.Object {
+			if _, err := integerArguments("floor", 1, args); err != nil {
+				return err
+			}
+			return args[0]
+		},
+	},
+	"ceil": {
+		Fn: func(args ...object.Object) object.Object {
+			if _, err := integerArguments("ceil", 1, args); err != nil {
+				return err
+			}
+			return args[0]
+		},
+	},
+	// floor_div(a, b) divides a by b rounding towards negative
+	// infinity, whereas / rounds towards zero
+	"floor_div": {
+		Fn: func(args ...object.Object) object.Object {
+			return roundedDiv("floor_div", args, false)
+		},
+	},
+	// ceil_div(a, b) divides a by b rounding towards positive infinity
+	"ceil_div": {
+		Fn: func(args ...object.Object) object.Object {
+			return roundedDiv("ceil_div", args, true)
+		},
+	},
+	"clamp": {
+		Fn: func(args ...object.Object) object.Object {
+			ints, err := integerArguments("clamp", 3, args)
+			if err != nil {
+				return err
+			}
+			x, lo, hi := ints[0], ints[1], ints[2]
+			if lo > hi {
+				return newError("clamp bounds out of order: %d > %d", lo, hi)
+			}
+			if x < lo {
+				x = lo
+			} else if x > hi {
+				x = hi
+			}
+			return &object.Integer{Value: x}
+		},
+	},
+	"gcd": {
+		Fn: func(args ...object.Object) object.Object {
+			ints, err := integerArguments("gcd", 2, args)
+			if err != nil {
+				return err
+			}
+			a, b := ints[0], ints[1]
+			for b != 0 {
+				a, b = b, a%b
+			}
+			if a == math.MinInt64 {
+				return newError("integer overflow: gcd(%d, %d)", ints[0], ints[1])
+			}
+			if a < 0 {
+				a = -a
+			}
+			return &object.Integer{Value: a}
+		},
+	},
+	// random returns a non-negative integer
+	"random": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0",
+					len(args))
+			}
+			randomMu.Lock()
+			defer randomMu.Unlock()
+			return &object.Integer{Value: random.Int63()}
+		},
+	},
+	// rand_int(lo, hi) returns an integer between lo and hi, inclusive
+	"rand_int": {
+		Fn: func(args ...object.Object) object.Object {
+			ints, err := integerArguments("rand_int", 2, args)
+			if err != nil {
+				return err
+			}
+			lo, hi := ints[0], ints[1]
+			if lo > hi {
+				return newError("rand_int bounds out of order: %d > %d", lo, hi)
+			}
+			span := uint64(hi - lo)
+			randomMu.Lock()
+			defer randomMu.Unlock()
+			switch {
+			case span < math.MaxInt64:
+				return &object.Integer{Value: lo + random.Int63n(int64(span)+1)}
+			case span == math.MaxUint64:
+				return &object.Integer{Value: int64(random.Uint64())}
+			default:
+				return &object.Integer{Value: lo + int64(random.Uint64()%(span+1))}
+			}
+		},
+	},
+}
+
+// integerArguments checks that args holds want arguments,
+// all integers, and returns their values or an error
+func integerArguments(name string, want int, args []object.Object) ([]int64, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args), want)
+	}
+	ints := make([]int64, want)
+	for i, arg := range args {
+		integer, ok := arg.(*object.Integer)
+		if !ok {
+			return nil, newError("argument to `%s` must be INTEGER, got %s",
+				name, arg.Type())
+		}
+		ints[i] = integer.Value
+	}
+	return ints, nil
+}
+
+// multiply returns a * b and whether it fits in an int64
+func multiply(a, b int64) (int64, bool) {
+	c := a * b
+	if a != 0 && (c/a != b || a == -1 && b == math.MinInt64) {
+		return 0, false
+	}
+	return c, true
+}
+
+// roundedDiv divides the two integers of args, rounding
+// towards positive infinity if up and negative otherwise
+func roundedDiv(name string, args []object.Object, up bool) object.Object {
+	ints, err := integerArguments(name, 2, args)
+	if err != nil {
+		return err
+	}
+	a, b := ints[0], ints[1]
+	if b == 0 {
+		return newError("division by zero")
+	}
+	if a == math.MinInt64 && b == -1 {
+		return newError("integer overflow: %s(%d, %d)", name, a, b)
+	}
+	// / rounds towards zero, which is up for a negative quotient
+	q := a / b
+	if a%b != 0 {
+		positive := (a < 0) == (b < 0)
+		if up && positive {
+			q++
+		} else if !up && !positive {
+			q--
+		}
+	}
+	return &object.Integer{Value: q}
+}
+
+// extremum implements min and max, which take either a
+// single array or one or more integers
+func extremum(name string, args []object.Object, better func(a, b int64) bool) object.Object {
+	if len(args) == 1 {
+		if arr, ok := args[0].(*object.Array); ok {
+			if len(arr.Elements) == 0 {
+				return newError("argument to `%s` is an empty ARRAY", name)
+			}
+			args = arr.Elements
+		}
+	}
+	if len(args) == 0 {
+		return newError("wrong number of arguments. got=0, want at least 1")
+	}
+	ints, err := integerArguments(name, len(args), args)
+	if err != nil {
+		return err
+	}
+	best := 0
+	for i, n := range ints {
+		if better(n, ints[best]) {
+			best = i
+		}
+	}
+	return args[best]
+}
@@ -0,0 +1,262 @@
+package evaluator
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lindeneg/monkey/object"
+)
+
+func init() {
+	registerBuiltins(stringBuiltins)
+}
+
+// maxRepeatLength is the length in bytes of the longest string `repeat`
+// will build, so that a large count fails instead of exhausting memory
+const maxRepeatLength = 1 << 30
+
+// Builtins operating on strings. Indices and lengths are in runes,
+// the same as for string indexing. Joining is done by `join`.
+var stringBuiltins = map[string]*object.Builtin{
+	// split(s, sep) splits s around each sep, or into
+	// single characters if sep is empty
+	"split": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("split", 2, args)
+			if err != nil {
+				return err
+			}
+			return stringArray(strings.Split(strs[0], strs[1]))
+		},
+	},
+	// trim(s) removes leading and trailing white space,
+	// trim(s, cutset) the characters found in cutset
+	"trim": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 2 {
+				strs, err := stringArguments("trim", 2, args)
+				if err != nil {
+					return err
+				}
+				return &object.String{Value: strings.Trim(strs[0], strs[1])}
+			}
+			strs, err := stringArguments("trim", 1, args)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.TrimSpace(strs[0])}
+		},
+	},
+	"upper": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("upper", 1, args)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToUpper(strs[0])}
+		},
+	},
+	"lower": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("lower", 1, args)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToLower(strs[0])}
+		},
+	},
+	"contains": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("contains", 2, args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.Contains(strs[0], strs[1]))
+		},
+	},
+	"starts_with": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("starts_with", 2, args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.HasPrefix(strs[0], strs[1]))
+		},
+	},
+	"ends_with": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("ends_with", 2, args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.HasSuffix(strs[0], strs[1]))
+		},
+	},
+	// replace(s, old, new) replaces every occurrence of old
+	"replace": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("replace", 3, args)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ReplaceAll(strs[0], strs[1], strs[2])}
+		},
+	},
+	// index_of(s, sub) returns the rune index of the
+	// first occurrence of sub in s, or -1
+	"index_of": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("index_of", 2, args)
+			if err != nil {
+				return err
+			}
+			i := strings.Index(strs[0], strs[1])
+			if i > 0 {
+				i = utf8.RuneCountInString(strs[0][:i])
+			}
+			return &object.Integer{Value: int64(i)}
+		},
+	},
+	"repeat": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `repeat` must be STRING, got %s",
+					args[0].Type())
+			}
+			count, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("argument to `repeat` must be INTEGER, got %s",
+					args[1].Type())
+			}
+			if count.Value < 0 {
+				return newError("negative repeat count: %d", count.Value)
+			}
+			if len(str.Value) > 0 && count.Value > maxRepeatLength/int64(len(str.Value)) {
+				return newError("repeat count too large: %d", count.Value)
+			}
+			return &object.String{Value: strings.Repeat(str.Value, int(count.Value))}
+		},
+	},
+	"chars": {
+		Fn: func(args ...object.Object) object.Object {
+			strs, err := stringArguments("chars", 1, args)
+			if err != nil {
+				return err
+			}
+			return stringArray(strings.Split(strs[0], ""))
+		},
+	},
+	"format":  {Fn: formatBuiltin},
+	"sprintf": {Fn: formatBuiltin},
+}
+
+// formatBuiltin implements format(template, args...), which supports
+//
+//	%s  a string as is, any other value as it is inspected
+//	%d  an integer
+//	%t  a boolean
+//	%q  a string, quoted
+//	%v  any value as it is inspected
+//	%%  a literal percent sign
+func formatBuiltin(args ...object.Object) object.Object {
+	if len(args) < 1 {
+		return newError("wrong number of arguments. got=%d, want at least 1",
+			len(args))
+	}
+	template, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `format` must be STRING, got %s",
+			args[0].Type())
+	}
+	values := args[1:]
+	var out strings.Builder
+	next := 0
+	for i := 0; i < len(template.Value); i++ {
+		c := template.Value[i]
+		if c != '%' {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(template.Value) {
+			return newError("format: missing verb at end of template")
+		}
+		verb, size := utf8.DecodeRuneInString(template.Value[i:])
+		i += size - 1
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if next == len(values) {
+			return newError("format: missing argument for %%%c", verb)
+		}
+		value := values[next]
+		next++
+		switch verb {
+		case 's':
+			if str, ok := value.(*object.String); ok {
+				out.WriteString(str.Value)
+			} else {
+				out.WriteString(value.Inspect())
+			}
+		case 'v':
+			out.WriteString(value.Inspect())
+		case 'd':
+			integer, ok := value.(*object.Integer)
+			if !ok {
+				return newError("format: %%d needs INTEGER, got %s", value.Type())
+			}
+			out.WriteString(strconv.FormatInt(integer.Value, 10))
+		case 't':
+			if value.Type() != object.BOOLEAN_OBJ {
+				return newError("format: %%t needs BOOLEAN, got %s", value.Type())
+			}
+			out.WriteString(value.Inspect())
+		case 'q':
+			str, ok := value.(*object.String)
+			if !ok {
+				return newError("format: %%q needs STRING, got %s", value.Type())
+			}
+			out.WriteString(strconv.Quote(str.Value))
+		default:
+			return newError("format: unknown verb %%%c", verb)
+		}
+	}
+	if next < len(values) {
+		return newError("format: %d unused arguments", len(values)-next)
+	}
+	return &object.String{Value: out.String()}
+}
+
+// stringArguments checks that args holds want arguments,
+// all strings, and returns their values or an error
+func stringArguments(name string, want int, args []object.Object) ([]string, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args), want)
+	}
+	strs := make([]string, want)
+	for i, arg := range args {
+		str, ok := arg.(*object.String)
+		if !ok {
+			return nil, newError("argument to `%s` must be STRING, got %s",
+				name, arg.Type())
+		}
+		strs[i] = str.Value
+	}
+	return strs, nil
+}
+
+func stringArray(strs []string) *object.Array {
+	elements := make([]object.Object, len(strs))
+	for i, s := range strs {
+		elements[i] = &object.String{Value: s}
+	}
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,380 @@
+package evaluator
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/lindeneg/monkey/object"
+)
+
+// testInspect evaluates each input and compares the
+// Inspect output of the result with the expected one
+func testInspect(t *testing.T, tests []struct {
+	input    string
+	expected string
+}) {
+	t.Helper()
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: evaluated to nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestHashBuiltins(t *testing.T) {
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{`len({})`, "0"},
+		{`len({"a": 1, "b": 2})`, "2"},
+		{`keys({"b": 1, "a": 2, 3: 3})`, "[b, a, 3]"},
+		{`keys({})`, "[]"},
+		{`values({"b": 1, "a": 2, 3: true})`, "[1, 2, true]"},
+		{`entries({"b": 1, "a": 2})`, "[[b, 1], [a, 2]]"},
+		{`has({"a": 1}, "a")`, "true"},
+		{`has({"a": 1}, "b")`, "false"},
+		{`has({[1, 2]: 1}, [1, 2])`, "true"},
+		{`has({"a": 1}, fn(x) { x })`, "ERROR: unusable as hash key: FUNCTION"},
+		{`delete({"a": 1, "b": 2, "c": 3}, "b")`, "{a: 1, c: 3}"},
+		{`delete({"a": 1}, "z")`, "{a: 1}"},
+		{`let h = {"a": 1, "b": 2}; let d = delete(h, "a"); h`, "{a: 1, b: 2}"},
+		{`let d = delete({"a": 1, "b": 2}, "a"); d["b"]`, "2"},
+		{`let d = delete({"a": 1, "b": 2}, "a"); d["a"]`, "null"},
+		{`merge({"a": 1, "b": 2}, {"b": 3, "c": 4})`, "{a: 1, b: 3, c: 4}"},
+		{`merge({"a": 1}, {"b": 2}, {"a": 3})`, "{a: 3, b: 2}"},
+		{`let h = {"a": 1}; let m = merge(h, {"a": 2}); h`, "{a: 1}"},
+		{`keys([1])`, "ERROR: argument to `keys` must be HASH, got ARRAY"},
+		{`values({}, {})`, "ERROR: wrong number of arguments. got=2, want=1"},
+		{`has({})`, "ERROR: wrong number of arguments. got=1, want=2"},
+		{`merge({})`, "ERROR: wrong number of arguments. got=1, want at least 2"},
+		{`merge({}, 1)`, "ERROR: argument to `merge` must be HASH, got INTEGER"},
+	})
+}
+
+func TestStringFirstLastRest(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`first("abc")`, "a"},
+		{`last("abc")`, "c"},
+		{`rest("abc")`, "bc"},
+		{`first("éa")`, "é"},
+		{`last("aé")`, "é"},
+		{`rest("éa")`, "a"},
+		{`rest("a")`, ""},
+		{`first("")`, "null"},
+		{`rest("")`, "null"},
+	}
+	testInspect(t, tests)
+}
+
+func TestArrayBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map([1, 2, 3], fn(x) { x * 2 })", "[2, 4, 6]"},
+		{"map([], fn(x) { x })", "[]"},
+		{"map([1, 2], len)", "ERROR: argument to `len` not supported, got INTEGER"},
+		{"map([1], 1)", "ERROR: argument to `map` must be FUNCTION, got INTEGER"},
+		{"map(1, fn(x) { x })", "ERROR: argument to `map` must be ARRAY, got INTEGER"},
+		{"map([1, 2], fn(x) { return x + 1; })", "[2, 3]"},
+		{"map([1, 2], fn(x) { let y = x; })", "[null, null]"},
+		{"filter([1, 2], fn(x) { let y = x; })", "[]"},
+		{"reduce([1, 2], 0, fn(acc, x) { let y = x; })", "null"},
+		{"filter([1, 2, 3, 4], fn(x) { x > 2 })", "[3, 4]"},
+		{"reduce([1, 2, 3, 4], 0, fn(acc, x) { acc + x })", "10"},
+		{"reduce([], 5, fn(acc, x) { acc + x })", "5"},
+		{"reduce([1], 0, fn(x) { x })", "ERROR: fn takes 1 args but 2 was passed"},
+		{"find([1, 2, 3], fn(x) { x > 1 })", "2"},
+		{"find([1, 2, 3], fn(x) { x > 3 })", "null"},
+		{"any([1, 2, 3], fn(x) { x == 2 })", "true"},
+		{"any([], fn(x) { true })", "false"},
+		{"all([1, 2, 3], fn(x) { x > 0 })", "true"},
+		{"all([1, 2, 3], fn(x) { x > 1 })", "false"},
+		{"sort([3, 1, 2])", "[1, 2, 3]"},
+		{`sort(["b", "c", "a"])`, "[a, b, c]"},
+		{"sort([3, 1, 2], fn(a, b) { a > b })", "[3, 2, 1]"},
+		{`sort([1, "a"])`, "ERROR: cannot compare STRING and INTEGER"},
+		{"sort([2, 1], fn(a, b) { a + true })", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"let a = [2, 1]; sort(a); a", "[2, 1]"},
+		{"reverse([1, 2, 3])", "[3, 2, 1]"},
+		{"zip([1, 2, 3], [4, 5])", "[[1, 4], [2, 5]]"},
+		{"zip([1])", "ERROR: wrong number of arguments. got=1, want at least 2"},
+		{"flatten([1, [2, 3], [[4]]])", "[1, 2, 3, [4]]"},
+		{"range(3)", "[0, 1, 2]"},
+		{"range(1, 4)", "[1, 2, 3]"},
+		{"range(5, 0, -2)", "[5, 3, 1]"},
+		{"range(1, 0)", "[]"},
+		{"range(1, 10, 9223372036854775807)", "[1]"},
+		{"range(0, 9223372036854775807, 4611686018427387904)", "[0, 4611686018427387904]"},
+		{"range(9223372036854775807, -9223372036854775807, -9223372036854775807)", "[9223372036854775807, 0]"},
+		{"range(0, 9223372036854775807)", "ERROR: range too long: 9223372036854775807 elements"},
+		{"range(0, 5, 0)", "ERROR: range step cannot be zero"},
+		{`join([1, "a", true], ", ")`, "1, a, true"},
+		{`join(["a", "b"])`, "ab"},
+		{`join([], "-")`, ""},
+	}
+	testInspect(t, tests)
+}
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`split("a,b,c", ",")`, "[a, b, c]"},
+		{`split("abc", "")`, "[a, b, c]"},
+		{`split("abc", 1)`, "ERROR: argument to `split` must be STRING, got INTEGER"},
+		{`join(split("a b", " "), "-")`, "a-b"},
+		{`trim("  a b  ")`, "a b"},
+		{`trim("xxaxx", "x")`, "a"},
+		{`upper("abc")`, "ABC"},
+		{`lower("ABC")`, "abc"},
+		{`lower()`, "ERROR: wrong number of arguments. got=0, want=1"},
+		{`contains("hello", "ell")`, "true"},
+		{`contains("hello", "x")`, "false"},
+		{`starts_with("hello", "he")`, "true"},
+		{`ends_with("hello", "he")`, "false"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`index_of("héllo", "l")`, "2"},
+		{`index_of("hello", "x")`, "-1"},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", -1)`, "ERROR: negative repeat count: -1"},
+		{`repeat("ab", 4611686018427387904)`, "ERROR: repeat count too large: 4611686018427387904"},
+		{`len(repeat("", 4611686018427387904))`, "0"},
+		{`repeat("ab", "3")`, "ERROR: argument to `repeat` must be INTEGER, got STRING"},
+		{`chars("hé")`, "[h, é]"},
+		{`chars("")`, "[]"},
+		{`format("%s is %d", "x", 5)`, "x is 5"},
+		{`sprintf("%v and %s", [1, "a"], [1])`, "[1, a] and [1]"},
+		{`format("%q %t 100%%", "a", true)`, `"a" true 100%`},
+		{`format("%d", "a")`, "ERROR: format: %d needs INTEGER, got STRING"},
+		{`format("%d %d", 1)`, "ERROR: format: missing argument for %d"},
+		{`format("%d", 1, 2)`, "ERROR: format: 1 unused arguments"},
+		{`format("%x", 1)`, "ERROR: format: unknown verb %x"},
+		{`format("100%")`, "ERROR: format: missing verb at end of template"},
+	}
+	testInspect(t, tests)
+}
+
+func TestTypeBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`type(1)`, "INTEGER"},
+		{`type("a")`, "STRING"},
+		{`type(true)`, "BOOLEAN"},
+		{`type([])`, "ARRAY"},
+		{`type({})`, "HASH"},
+		{`type(fn() {})`, "FUNCTION"},
+		{`type(len)`, "BUILTIN"},
+		{`type(first([]))`, "NULL"},
+		{`type()`, "ERROR: wrong number of arguments. got=0, want=1"},
+		{`str(12) + "!"`, "12!"},
+		{`str("a")`, "a"},
+		{`str([1, "a"])`, "[1, a]"},
+		{`int("42") + 1`, "43"},
+		{`int("-7")`, "-7"},
+		{`int(true)`, "1"},
+		{`int(5)`, "5"},
+		{`int("4x")`, `error: could not parse "4x" as INTEGER`},
+		{`int([])`, "ERROR: argument to `int` not supported, got ARRAY"},
+		{`bool(0)`, "false"},
+		{`bool("")`, "true"},
+		{`bool(first([]))`, "false"},
+		{`is_int(1)`, "true"},
+		{`is_int("1")`, "false"},
+		{`is_string("1")`, "true"},
+		{`is_bool(false)`, "true"},
+		{`is_array([])`, "true"},
+		{`is_hash({})`, "true"},
+		{`is_null(first([]))`, "true"},
+		{"let f = fn() {}; is_null(f())", "true"},
+		{"let f = fn() {}; type(f())", "NULL"},
+		{"let f = fn() {}; str(f())", "null"},
+		{"str(if (true) {})", "null"},
+		{`is_fn(fn() {})`, "true"},
+		{`is_fn(len)`, "true"},
+		{`is_fn(1)`, "false"},
+	}
+	testInspect(t, tests)
+}
+
+func TestErrorValues(t *testing.T) {
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{`error("bad input")`, "error: bad input"},
+		{`type(error("bad"))`, "ERROR_VALUE"},
+		{`is_error(error("bad"))`, "true"},
+		{`is_error(int("x"))`, "true"},
+		{`is_error(int("1"))`, "false"},
+		{`is_error("bad")`, "false"},
+		{`error_message(int("x"))`, `could not parse "x" as INTEGER`},
+		{`error("bad")["message"]`, "bad"},
+		{`error("bad")["line"]`, `ERROR: error value has no member "line"`},
+		{`error("bad")[0]`, "ERROR: error value member must be STRING, got INTEGER"},
+		{`let n = int("x"); if (is_error(n)) { 0 } else { n }`, "0"},
+		{`let e = error("a"); [e, 1]`, "[error: a, 1]"},
+		{`try { throw error("bad"); } catch (e) { [e["message"], is_error(e["value"])] }`, "[bad, true]"},
+		{`error(1)`, "ERROR: argument to `error` must be STRING, got INTEGER"},
+		{`error_message("bad")`, "ERROR: argument to `error_message` must be ERROR_VALUE, got STRING"},
+		{`error_message()`, "ERROR: wrong number of arguments. got=0, want=1"},
+	})
+}
+
+func TestMathBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"abs(-5)", "5"},
+		{"abs(5)", "5"},
+		{"abs(-9223372036854775807 - 1)", "ERROR: integer overflow: abs(-9223372036854775808)"},
+		{`abs("5")`, "ERROR: argument to `abs` must be INTEGER, got STRING"},
+		{"min(3, 1, 2)", "1"},
+		{"max(3, 1, 2)", "3"},
+		{"min([4, -2, 7])", "-2"},
+		{"max([])", "ERROR: argument to `max` is an empty ARRAY"},
+		{"min()", "ERROR: wrong number of arguments. got=0, want at least 1"},
+		{"pow(2, 10)", "1024"},
+		{"pow(-3, 3)", "-27"},
+		{"pow(5, 0)", "1"},
+		{"pow(2, 62)", "4611686018427387904"},
+		{"pow(-2, 63)", "-9223372036854775808"},
+		{"pow(-1, 9223372036854775807)", "-1"},
+		{"pow(2, 63)", "ERROR: integer overflow: pow(2, 63)"},
+		{"pow(2, 64)", "ERROR: integer overflow: pow(2, 64)"},
+		{"pow(3, 40)", "ERROR: integer overflow: pow(3, 40)"},
+		{"pow(2, -1)", "ERROR: negative exponent: -1"},
+		{"sqrt(0)", "0"},
+		{"sqrt(1)", "1"},
+		{"sqrt(15)", "3"},
+		{"sqrt(16)", "4"},
+		{"sqrt(9223372036854775807)", "3037000499"},
+		{"sqrt(-1)", "ERROR: square root of negative number: -1"},
+		{"floor_div(7, 2)", "3"},
+		{"floor_div(-7, 2)", "-4"},
+		{"floor_div(-6, 2)", "-3"},
+		{"ceil_div(7, 2)", "4"},
+		{"ceil_div(-7, 2)", "-3"},
+		{"ceil_div(6, 2)", "3"},
+		{"floor_div(1, 0)", "ERROR: division by zero"},
+		{"ceil_div(1, 0)", "ERROR: division by zero"},
+		{"floor(7)", "7"},
+		{"ceil(-7)", "-7"},
+		{`ceil("7")`, "ERROR: argument to `ceil` must be INTEGER, got STRING"},
+		{"floor(7, 2)", "ERROR: wrong number of arguments. got=2, want=1"},
+		{"floor_div(-9223372036854775807 - 1, -1)", "ERROR: integer overflow: floor_div(-9223372036854775808, -1)"},
+		{"clamp(5, 0, 3)", "3"},
+		{"clamp(-5, 0, 3)", "0"},
+		{"clamp(2, 0, 3)", "2"},
+		{"clamp(2, 3, 0)", "ERROR: clamp bounds out of order: 3 > 0"},
+		{"gcd(12, 18)", "6"},
+		{"gcd(-12, 18)", "6"},
+		{"gcd(0, 5)", "5"},
+		{"gcd(-9223372036854775807 - 1, 0)", "ERROR: integer overflow: gcd(-9223372036854775808, 0)"},
+		{"random(1)", "ERROR: wrong number of arguments. got=1, want=0"},
+		{"rand_int(3, 3)", "3"},
+		{"rand_int(3, 1)", "ERROR: rand_int bounds out of order: 3 > 1"},
+	}
+	testInspect(t, tests)
+}
+
+func TestSeedRandom(t *testing.T) {
+	input := "[random(), rand_int(1, 6), rand_int(-100, 100)]"
+	SeedRandom(42)
+	first := testEval(input).Inspect()
+	SeedRandom(42)
+	second := testEval(input).Inspect()
+	if first != second {
+		t.Fatalf("same seed gave different results: %s and %s", first, second)
+	}
+	for i := 0; i < 100; i++ {
+		n := testEval("rand_int(1, 6)")
+		testIntegerRange(t, n, 1, 6)
+	}
+}
+
+func TestRandomConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			SeedRandom(seed)
+			for j := 0; j < 50; j++ {
+				testEval("random(); rand_int(1, 6)")
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+}
+
+func testIntegerRange(t *testing.T, obj object.Object, lo, hi int64) {
+	t.Helper()
+	integer, ok := obj.(*object.Integer)
+	if !ok {
+		t.Fatalf("object is not Integer. got=%T (%+v)", obj, obj)
+	}
+	if integer.Value < lo || integer.Value > hi {
+		t.Fatalf("%d is not between %d and %d", integer.Value, lo, hi)
+	}
+}
+
+func TestJSONBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json_encode({"b": [1, true, first([])], "a": "x<y", 1: 2})`,
+			`{"b":[1,true,null],"a":"x<y","1":2}`},
+		{`json_encode({"a": [1]}, 2)`, "{\n  \"a\": [\n    1\n  ]\n}"},
+		{`json_encode([], "\t")`, "[]"},
+		{"let f = fn() {}; json_encode([f()])", "[null]"},
+		{`json_encode(fn(x) { x })`, "ERROR: cannot encode FUNCTION as JSON"},
+		{`json_encode({"f": len})`, "ERROR: cannot encode BUILTIN as JSON"},
+		{`json_encode({[1]: 1})`, "ERROR: cannot encode ARRAY hash key as JSON"},
+		{`json_encode(1, 10)`, "1"},
+		{`json_encode(1, 11)`, "ERROR: indent too large: 11, max is 10"},
+		{`json_encode(1, 9223372036854775807)`, "ERROR: indent too large: 9223372036854775807, max is 10"},
+		{`json_encode(1, -1)`, "ERROR: negative indent: -1"},
+		{`json_encode(1, true)`, "ERROR: argument to `json_encode` must be STRING or INTEGER, got BOOLEAN"},
+		{`json_decode(json_encode({"z": 1, "a": [1, {"b": []}]}))`, "{z: 1, a: [1, {b: []}]}"},
+		{`json_decode(1)`, "ERROR: argument to `json_decode` must be STRING, got INTEGER"},
+	}
+	testInspect(t, tests)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"z": 1, "a": [true, null, -2], "c": "s\n"}`, "{z: 1, a: [true, null, -2], c: s\n}"},
+		{`{"a": 1, "a": 2}`, "{a: 2}"},
+		{" [ ] ", "[]"},
+		{"[1, 2", "ERROR: json_decode: unexpected end of JSON input at 1:6"},
+		{"[1,\n 2 x]", "ERROR: json_decode: invalid character 'x' after array element at 2:4"},
+		{`{"a" 1}`, "ERROR: json_decode: invalid character '1' after object key at 1:6"},
+		{"1.5", "ERROR: json_decode: cannot represent 1.5 as INTEGER at 1:4"},
+		{"1 2", "ERROR: json_decode: unexpected data after top-level value at 1:4"},
+		{"", "ERROR: json_decode: unexpected EOF at 1:1"},
+	}
+	for _, tt := range tests {
+		result := decodeJSON(tt.input)
+		if result.Inspect() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
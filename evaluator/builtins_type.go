@@ -0,0 +1,104 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lindeneg/monkey/object"
+)
+
+func init() {
+	registerBuiltins(typeBuiltins)
+}
+
+// Builtins for asking what type a value is and for converting
+// between types. Type names are those used in error messages.
+var typeBuiltins = map[string]*object.Builtin{
+	"type": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+			return &object.String{Value: string(args[0].Type())}
+		},
+	},
+	"str": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+			if str, ok := args[0].(*object.String); ok {
+				return str
+			}
+			return &object.String{Value: args[0].Inspect()}
+		},
+	},
+	// int converts strings holding a decimal integer and
+	// booleans, true being 1 and false 0. A string that does
+	// not hold an integer gives an error value.
+	"int": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return arg
+			case *object.Boolean:
+				if arg.Value {
+					return &object.Integer{Value: 1}
+				}
+				return &object.Integer{Value: 0}
+			case *object.String:
+				value, err := strconv.ParseInt(arg.Value, 10, 64)
+				if err != nil {
+					return &object.ErrorValue{
+						Message: fmt.Sprintf("could not parse %q as INTEGER", arg.Value),
+					}
+				}
+				return &object.Integer{Value: value}
+			default:
+				return newError("argument to `int` not supported, got %s",
+					args[0].Type())
+			}
+		},
+	},
+	"bool": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+			return nativeBoolToBooleanObject(isTruthy(args[0]))
+		},
+	},
+	"is_int":    typePredicate(object.INTEGER_OBJ),
+	"is_string": typePredicate(object.STRING_OBJ),
+	"is_bool":   typePredicate(object.BOOLEAN_OBJ),
+	"is_array":  typePredicate(object.ARRAY_OBJ),
+	"is_hash":   typePredicate(object.HASH_OBJ),
+	"is_null":   typePredicate(object.NULL_OBJ),
+	"is_fn":     typePredicate(object.FUNCTION_OBJ, object.BUILTIN_OBJ),
+}
+
+// typePredicate returns a builtin reporting whether
+// its argument is of one of the given types
+func typePredicate(types ...object.ObjectType) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+			for _, t := range types {
+				if args[0].Type() == t {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/lindeneg/monkey/object"
+)
+
+// callContext is handed to builtins that call functions. A
+// function yielding no value returns NULL so that builtins
+// can store the result.
+type callContext struct{}
+
+func (callContext) Call(fn object.Object, args ...object.Object) object.Object {
+	return applyFunction(fn, args)
+}
+
+// Error is the Go error for an error raised in Monkey code,
+// keeping the line, stack and thrown value of Object
+type Error struct {
+	Object *object.Error
+}
+
+func (e *Error) Error() string { return e.Object.Message }
+
+// Call applies fn, a function or builtin, to args. An error
+// raised while evaluating the call is returned as an *Error.
+// A function that yields no value returns NULL.
+func Call(fn object.Object, args ...object.Object) (object.Object, error) {
+	result := applyFunction(fn, args)
+	if errObj, ok := result.(*object.Error); ok {
+		return nil, &Error{Object: errObj}
+	}
+	return result, nil
+}
+
+// CallFunction calls the function bound to name in env,
+// typically one defined by a script evaluated in env
+func CallFunction(env *object.Environment, name string, args ...object.Object) (object.Object, error) {
+	fn, ok := env.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("function not found: %s", name)
+	}
+	return Call(fn, args...)
+}
@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/object"
+	"github.com/lindeneg/monkey/parser"
+)
+
+func TestCallFunction(t *testing.T) {
+	input := `
+let add = fn(a, b) { return a + b; };
+let handlers = {"double": fn(x) { x * 2 }};
+let broken = fn() { 1 + true };
+let nothing = fn() { let x = 1; };
+let thrower = fn() {
+  throw {"message": "bad", "code": 7};
+};
+`
+	env := object.NewEnvironment()
+	Eval(parser.New(lexer.NewLexer(input)).ParseProgram(), env)
+
+	result, err := CallFunction(env, "add", &object.Integer{Value: 2}, &object.Integer{Value: 3})
+	if err != nil {
+		t.Fatalf("CallFunction returned error: %s", err)
+	}
+	testIntegerObject(t, result, 5)
+
+	handlers, _ := env.Get("handlers")
+	double, _ := handlers.(*object.Hash).Get(&object.String{Value: "double"})
+	result, err = Call(double, &object.Integer{Value: 4})
+	if err != nil {
+		t.Fatalf("Call returned error: %s", err)
+	}
+	testIntegerObject(t, result, 8)
+
+	result, err = CallFunction(env, "nothing")
+	if err != nil || result != NULL {
+		t.Errorf("CallFunction(nothing): expected NULL, got %v (%v)", result, err)
+	}
+
+	_, err = CallFunction(env, "thrower")
+	var callErr *Error
+	if !errors.As(err, &callErr) {
+		t.Fatalf("CallFunction(thrower): expected *Error, got %T", err)
+	}
+	if callErr.Error() != "bad" || callErr.Object.Line != 7 {
+		t.Errorf("wrong error. message=%q, line=%d", callErr.Error(), callErr.Object.Line)
+	}
+	if code, _ := callErr.Object.Value.(*object.Hash).Get(&object.String{Value: "code"}); code.Inspect() != "7" {
+		t.Errorf("thrown value not kept, got %s", callErr.Object.Value.Inspect())
+	}
+
+	tests := []struct {
+		name     string
+		args     []object.Object
+		expected string
+	}{
+		{"missing", nil, "function not found: missing"},
+		{"broken", nil, "type mismatch: INTEGER + BOOLEAN"},
+		{"add", nil, "fn takes 2 args but 0 was passed"},
+		{"handlers", nil, "not a function: HASH"},
+	}
+	for _, tt := range tests {
+		_, err := CallFunction(env, tt.name, tt.args...)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("CallFunction(%s): expected error %q, got %v", tt.name, tt.expected, err)
+		}
+	}
+}
+
+func TestBuiltinCallContext(t *testing.T) {
+	builtin := &object.Builtin{
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			return ctx.Call(args[0], args[1:]...)
+		},
+	}
+	env := object.NewEnvironment()
+	env.Set("call", builtin)
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"call(fn(x) { return x + 1; }, 1)", "2"},
+		{"call(len, [1, 2])", "2"},
+		{"call(fn() { 1 + true })", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+	}
+	for _, tt := range tests {
+		evaluated := Eval(parser.New(lexer.NewLexer(tt.input)).ParseProgram(), env)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
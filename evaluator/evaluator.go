@@ -9,12 +9,25 @@ import (
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL  = object.NULL
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
 )
 
+// Eval evaluates n in e. An error resulting from it is given
+// the line and file of n, unless a node within n already set
+// them. Lines in the prelude are not set, so that an error raised
+// in it is given the line of the call into the prelude instead.
 func Eval(n ast.Node, e *object.Environment) object.Object {
+	result := eval(n, e)
+	if err, ok := result.(*object.Error); ok && err.Line == 0 && e.File() != preludeFile {
+		err.Line = ast.SpanOf(n).Start.Line
+		err.File = e.File()
+	}
+	return result
+}
+
+func eval(n ast.Node, e *object.Environment) object.Object {
 	switch node := n.(type) {
 	case *ast.Program:
 		return evalProgram(node.Statements, e)
@@ -29,6 +42,9 @@ func Eval(n ast.Node, e *object.Environment) object.Object {
 		if isError(val) {
 			return val
 		}
+		if node.Pattern != nil {
+			return evalDestructuringLet(node, val, e)
+		}
 		e.Set(node.Name.Value, val)
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, e)
@@ -46,6 +62,10 @@ func Eval(n ast.Node, e *object.Environment) object.Object {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, e)
+	case *ast.ImportExpression:
+		return evalImportExpression(node, e)
 	case *ast.CallExpression:
 		function := Eval(node.Function, e)
 		if isError(function) {
@@ -55,13 +75,23 @@ func Eval(n ast.Node, e *object.Environment) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		result := applyFunction(function, args)
+		if err, ok := result.(*object.Error); ok {
+			err.Stack = append(err.Stack, callFrame(node, e))
+		}
+		return result
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
 		return &object.Function{Parameters: params, Env: e, Body: body}
 	case *ast.IfExpression:
 		return evalIfExpression(node, e)
+	case *ast.TryExpression:
+		return evalTryExpression(node, e)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, e)
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, e)
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, e)
 		if isError(val) {
@@ -128,8 +158,14 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.MODULE_OBJ:
+		return evalModuleIndexExpression(left, index)
+	case left.Type() == object.ERROR_VALUE_OBJ:
+		return evalErrorValueIndexExpression(left, index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
@@ -137,51 +173,175 @@ func evalIndexExpression(left, index object.Object) object.Object {
 
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
-	key, ok := index.(object.Hashable)
+	key, ok := object.HashableKey(index)
 	if !ok {
 		return newError("unusable as hash key: %s", index.Type())
 	}
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	value, ok := hashObject.Get(key)
 	if !ok {
 		return NULL
 	}
-	return pair.Value
+	return value
 }
 
 func evalHashLiteral(
 	node *ast.HashLiteral,
 	env *object.Environment,
 ) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
-	for keyNode, valueNode := range node.Pairs {
-		key := Eval(keyNode, env)
+	hash := object.NewHash()
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
 		if isError(key) {
 			return key
 		}
-		hashKey, ok := key.(object.Hashable)
+		hashKey, ok := object.HashableKey(key)
 		if !ok {
 			return newError("unusable as hash key: %s", key.Type())
 		}
-		value := Eval(valueNode, env)
+		value := Eval(pair.Value, env)
 		if isError(value) {
 			return value
 		}
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(hashKey, value)
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
 	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-	if idx < 0 || idx > max {
+	length := int64(len(arrayObject.Elements))
+	if idx < 0 {
+		idx += length
+	}
+	if idx < 0 || idx >= length {
 		return NULL
 	}
 	return arrayObject.Elements[idx]
 }
 
+// evalStringIndexExpression indexes a string by rune,
+// returning the rune at that position as a string
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx := index.(*object.Integer).Value
+	length := int64(len(runes))
+	if idx < 0 {
+		idx += length
+	}
+	if idx < 0 || idx >= length {
+		return NULL
+	}
+	return &object.String{Value: string(runes[idx])}
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	var bounds [3]*object.Integer
+	for i, exp := range []ast.Expression{node.Start, node.End, node.Step} {
+		if exp == nil {
+			continue
+		}
+		val := Eval(exp, env)
+		if isError(val) {
+			return val
+		}
+		integer, ok := val.(*object.Integer)
+		if !ok {
+			return newError("slice indices must be INTEGER, got %s", val.Type())
+		}
+		bounds[i] = integer
+	}
+	switch left := left.(type) {
+	case *object.Array:
+		start, end, step, err := sliceIndices(int64(len(left.Elements)), bounds)
+		if err != nil {
+			return err
+		}
+		if step == 1 {
+			// arrays are never modified in place so the
+			// backing array can be shared with the result
+			if end < start {
+				end = start
+			}
+			return &object.Array{Elements: left.Elements[start:end:end]}
+		}
+		n := sliceLength(start, end, step)
+		elements := make([]object.Object, 0, n)
+		for k := int64(0); k < n; k++ {
+			elements = append(elements, left.Elements[start+k*step])
+		}
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		start, end, step, err := sliceIndices(int64(len(runes)), bounds)
+		if err != nil {
+			return err
+		}
+		n := sliceLength(start, end, step)
+		out := make([]rune, 0, n)
+		for k := int64(0); k < n; k++ {
+			out = append(out, runes[start+k*step])
+		}
+		return &object.String{Value: string(out)}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+// sliceIndices resolves the start, end and step of a slice over a
+// sequence of the given length the way Python does: negative indices
+// count from the end, out of range indices are clamped and omitted
+// bounds default to the whole sequence in the direction of step
+func sliceIndices(length int64, bounds [3]*object.Integer) (start, end, step int64, err *object.Error) {
+	step = 1
+	if bounds[2] != nil {
+		step = bounds[2].Value
+	}
+	if step == 0 {
+		return 0, 0, 0, newError("slice step cannot be zero")
+	}
+	lower, upper := int64(0), length
+	if step < 0 {
+		lower, upper = -1, length-1
+	}
+	resolve := func(bound *object.Integer, def int64) int64 {
+		if bound == nil {
+			return def
+		}
+		idx := bound.Value
+		if idx < 0 {
+			idx += length
+			if idx < lower {
+				idx = lower
+			}
+		} else if idx > upper {
+			idx = upper
+		}
+		return idx
+	}
+	if step > 0 {
+		return resolve(bounds[0], lower), resolve(bounds[1], upper), step, nil
+	}
+	return resolve(bounds[0], upper), resolve(bounds[1], lower), step, nil
+}
+
+// sliceLength returns the number of elements selected by the
+// resolved indices of a slice, without stepping past end as
+// that might overflow for large steps
+func sliceLength(start, end, step int64) int64 {
+	if step > 0 && start < end {
+		return (end-start-1)/step + 1
+	}
+	if step < 0 && start > end {
+		return (end-start+1)/step + 1
+	}
+	return 0
+}
+
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 	for _, e := range exps {
@@ -189,6 +349,9 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 		if isError(evaled) {
 			return []object.Object{evaled}
 		}
+		if evaled == nil {
+			evaled = NULL
+		}
 		result = append(result, evaled)
 	}
 	return result
@@ -201,6 +364,9 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	if builtin, ok := builtins[node.Value]; ok {
 		return builtin
 	}
+	if val, ok := prelude.Load().lookup(node.Value, env); ok {
+		return val
+	}
 	return newError("identifier not found: " + node.Value)
 }
 
@@ -211,9 +377,17 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 			return newError("fn takes %d args but %d was passed", len(fn.Parameters), len(args))
 		}
 		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		evaluated := unwrapReturnValue(Eval(fn.Body, extendedEnv))
+		// a function yielding no value returns NULL, so that
+		// its result can be passed on like any other value
+		if evaluated == nil {
+			return NULL
+		}
+		return evaluated
 	case *object.Builtin:
+		if fn.CtxFn != nil {
+			return fn.CtxFn(callContext{}, args...)
+		}
 		return fn.Fn(args...)
 	default:
 		return newError("not a function: %s", fn.Type())
@@ -274,13 +448,19 @@ func evalStringInfixExpression(
 	operator string,
 	left, right object.Object,
 ) object.Object {
-	if operator != "+" {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+	switch operator {
+	case token.PLUS:
+		return &object.String{Value: leftVal + rightVal}
+	case token.EQ:
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case token.NOT_EQ:
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
 		return newError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
-	leftVal := left.(*object.String).Value
-	rightVal := right.(*object.String).Value
-	return &object.String{Value: leftVal + rightVal}
 }
 
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
@@ -294,6 +474,9 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case token.ASTERISK:
 		return &object.Integer{Value: leftVal * rightVal}
 	case token.SLASH:
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case token.LT:
 		return nativeBoolToBooleanObject(leftVal < rightVal)
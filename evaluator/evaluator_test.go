@@ -79,6 +79,14 @@ func TestArrayIndexExpressions(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"[1, 2, 3][-4]",
 			nil,
 		},
 	}
@@ -93,6 +101,53 @@ func TestArrayIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestStringIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"abc"[0]`, "a"},
+		{`"abc"[2]`, "c"},
+		{`"abc"[-1]`, "c"},
+		{`"héllo"[1]`, "é"},
+		{`"abc"[3]`, "null"},
+		{`"abc"[-4]`, "null"},
+		{`""[0]`, "null"},
+		{`"abc"["a"]`, "ERROR: index operator not supported: STRING"},
+	}
+	testInspect(t, tests)
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][1:]", "[2, 3, 4]"},
+		{"[1, 2, 3, 4][:-1]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4][-2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4][::2]", "[1, 3]"},
+		{"[1, 2, 3, 4][::-1]", "[4, 3, 2, 1]"},
+		{"[1, 2, 3, 4][2:0:-1]", "[3, 2]"},
+		{"[1, 2, 3, 4][3:1]", "[]"},
+		{"[1, 2, 3, 4][-10:10]", "[1, 2, 3, 4]"},
+		{"let a = [1, 2, 3]; let b = a[1:]; push(b, 4); a", "[1, 2, 3]"},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"héllo"[:2]`, "hé"},
+		{`"hello"[-3:]`, "llo"},
+		{"[1, 2, 3][1:3:9223372036854775807]", "[2]"},
+		{"[1, 2, 3][::-9223372036854775807]", "[3]"},
+		{`"abc"[::9223372036854775807]`, "a"},
+		{"[1, 2][::0]", "ERROR: slice step cannot be zero"},
+		{`[1, 2]["a":]`, "ERROR: slice indices must be INTEGER, got STRING"},
+		{"5[1:]", "ERROR: slice operator not supported: INTEGER"},
+	}
+	testInspect(t, tests)
+}
+
 func TestArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 	evaluated := testEval(input)
@@ -214,6 +269,21 @@ func TestStringConcatenation(t *testing.T) {
 	}
 }
 
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" + "b" == "ab"`, true},
+	}
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -273,6 +343,14 @@ func TestErrorHandling(t *testing.T) {
 			`{"name": "Monkey"}[fn(x) { x }];`,
 			"unusable as hash key: FUNCTION",
 		},
+		{
+			`{[1, fn(x) { x }]: 1}`,
+			"unusable as hash key: ARRAY",
+		},
+		{
+			`{{"f": fn(x) { x }}: 1}`,
+			"unusable as hash key: HASH",
+		},
 		{
 			`
 if (10 > 1) {
@@ -320,6 +398,13 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`first([1, 2, 3])`, 1},
 		{`last([1, 2, 3])`, 3},
 		{`rest([])`, nil},
+		{`len("héllo")`, 5},
+		{`first("")`, nil},
+		{`last("")`, nil},
+		{`rest("")`, nil},
+		{`first(1)`, "argument to `first` must be ARRAY or STRING, got INTEGER"},
+		{`last(1)`, "argument to `last` must be ARRAY or STRING, got INTEGER"},
+		{`rest(1)`, "argument to `rest` must be ARRAY or STRING, got INTEGER"},
 		{`rest([1, 2, 3])`, []object.Object{
 			&object.Integer{Value: 2}, &object.Integer{Value: 3}}},
 		{`rest([1, 2, "foo"])`, []object.Object{
@@ -366,6 +451,40 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestDestructuringLetStatements(t *testing.T) {
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1, 2]; a + b", "3"},
+		{"let [a, b, ...rest] = [1, 2, 3, 4]; [a, b, rest]", "[1, 2, [3, 4]]"},
+		{"let [a, ...rest] = [1]; rest", "[]"},
+		{"let [...all] = [1, 2]; all", "[1, 2]"},
+		{"let [a, _, c] = [1, 2, 3]; [a, c]", "[1, 3]"},
+		{"let [a, b = 10] = [1]; a + b", "11"},
+		{"let [a, b = a * 2] = [4]; b", "8"},
+		{"let [a, b = 10] = [1, 2]; b", "2"},
+		{"let [[a, b], c] = [[1, 2], 3]; a + b + c", "6"},
+		{`let {"name": n, "age": a} = {"name": "ann", "age": 30, "x": 1}; [n, a]`, "[ann, 30]"},
+		{`let {"name": n, "role": r = "user"} = {"name": "bob"}; [n, r]`, "[bob, user]"},
+		{`let {"pos": [x, y]} = {"pos": [1, 2]}; x * y`, "2"},
+		{`let {1: one, true: yes} = {1: "a", true: "b"}; one + yes`, "ab"},
+		{"let f = fn(pair) { let [a, b] = pair; a - b }; f([5, 2])", "3"},
+		{"let a = 1; let f = fn() { let [a] = [2]; a }; [f(), a]", "[2, 1]"},
+		{"let [a, b] = 5;", "ERROR: cannot destructure [a, b]: expected ARRAY, got INTEGER"},
+		{"let [a, b] = [1];", "ERROR: cannot destructure [a, b]: missing element 1"},
+		{"let [a, b] = [1, 2, 3];", "ERROR: cannot destructure [a, b]: expected ARRAY of length 2, got 3"},
+		{`let {"name": n} = {"age": 1};`, "ERROR: cannot destructure {name:n}: missing key name"},
+		{`let {"name": n} = [1];`, "ERROR: cannot destructure {name:n}: expected HASH, got ARRAY"},
+		{"let [a, [b]] = [1, 2];", "ERROR: cannot destructure [a, [b]]: expected ARRAY, got INTEGER"},
+		{"let [1, a] = [2, 3];", "ERROR: cannot destructure [1, a]: expected 1, got 2"},
+		{"let [a, b = 1 + true] = [1];", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"let [a, b] = [1 + true];", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"try { let [a, b] = [1]; } catch (e) { 0 }; a", "ERROR: identifier not found: a"},
+		{`let a = 1; try { let [a, {"b": b}] = [2, 3]; } catch (e) { 0 }; a`, "1"},
+	})
+}
+
 func TestFunctionObject(t *testing.T) {
 	input := "fn(x) { x + 2; };"
 	evaluated := testEval(input)
@@ -436,23 +555,48 @@ false: 6
 	if !ok {
 		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
 	}
-	expected := map[object.HashKey]int64{
-		(&object.String{Value: "one"}).HashKey():   1,
-		(&object.String{Value: "two"}).HashKey():   2,
-		(&object.String{Value: "three"}).HashKey(): 3,
-		(&object.Integer{Value: 4}).HashKey():      4,
-		TRUE.HashKey():                             5,
-		FALSE.HashKey():                            6,
-	}
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
-	}
-	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+	expected := []struct {
+		key   object.Hashable
+		value int64
+	}{
+		{&object.String{Value: "one"}, 1},
+		{&object.String{Value: "two"}, 2},
+		{&object.String{Value: "three"}, 3},
+		{&object.Integer{Value: 4}, 4},
+		{TRUE, 5},
+		{FALSE, 6},
+	}
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
+	}
+	for i, tt := range expected {
+		value, ok := result.Get(tt.key)
 		if !ok {
 			t.Errorf("no pair for given key in Pairs")
+			continue
+		}
+		testIntegerObject(t, value, tt.value)
+		if key := result.Pairs()[i].Key; key.Inspect() != tt.key.Inspect() {
+			t.Errorf("pair %d has wrong key. expected=%s, got=%s",
+				i, tt.key.Inspect(), key.Inspect())
+		}
+	}
+}
+
+func TestHashInspectOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"b": 1, "a": 2, "c": 3}`, "{b: 1, a: 2, c: 3}"},
+		{`{3: "x", 1: "y", 2: "z", 1: "w"}`, "{3: x, 1: w, 2: z}"},
+		{`let n = 0; {"x": 1, "y": 2}`, "{x: 1, y: 2}"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect. expected=%q, got=%q", tt.expected, evaluated.Inspect())
 		}
-		testIntegerObject(t, pair.Value, expectedValue)
 	}
 }
 
@@ -523,6 +667,31 @@ func TestHashIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestCompositeHashKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{[1, 2]: 5}[[1, 2]]`, 5},
+		{`let p = [0, 1]; {[0, 1]: "a", [1, 0]: 5}[[1, 0]]`, 5},
+		{`{[1, 2]: 5}[[2, 1]]`, nil},
+		{`{[]: 5}[[]]`, 5},
+		{`{[[1], "x"]: 5}[[[1], "x"]]`, 5},
+		{`{{"a": 1, "b": 2}: 5}[{"b": 2, "a": 1}]`, 5},
+		{`{{"a": 1}: 5}[{"a": 2}]`, nil},
+		{`{[1, 2]: 4, [1, 2]: 5}[[1, 2]]`, 5},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
 	t.Helper()
 	result, ok := obj.(*object.Boolean)
@@ -0,0 +1,73 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lindeneg/monkey/ast"
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/object"
+	"github.com/lindeneg/monkey/parser"
+)
+
+// evalImportExpression loads the module at the path given, relative
+// to the file being evaluated. A file is only evaluated the first
+// time it is imported, later imports return the same module.
+func evalImportExpression(node *ast.ImportExpression, env *object.Environment) object.Object {
+	val := Eval(node.Path, env)
+	if isError(val) {
+		return val
+	}
+	name, ok := val.(*object.String)
+	if !ok {
+		return newError("argument to `import` must be STRING, got %s", val.Type())
+	}
+	path := name.Value
+	if !filepath.IsAbs(path) && env.File() != "" {
+		path = filepath.Join(filepath.Dir(env.File()), path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return newError("cannot import %q: %s", name.Value, err)
+	}
+
+	modules := env.Modules()
+	if mod, ok := modules.Get(path); ok {
+		return mod
+	}
+	if cycle := modules.Begin(path); cycle != nil {
+		return newError("import cycle: %s", strings.Join(cycle, " -> "))
+	}
+	var mod *object.Module
+	defer func() { modules.End(path, mod) }()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return newError("cannot import %q: %s", name.Value, err)
+	}
+	p := parser.New(lexer.NewLexer(string(data)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return newError("cannot import %q: %s", name.Value, strings.Join(p.Errors(), "; "))
+	}
+	moduleEnv := object.NewFileEnvironment(path, modules)
+	if result := Eval(program, moduleEnv); isError(result) {
+		return result
+	}
+	mod = &object.Module{Name: name.Value, Path: path, Env: moduleEnv}
+	return mod
+}
+
+func evalModuleIndexExpression(module, index object.Object) object.Object {
+	mod := module.(*object.Module)
+	name, ok := index.(*object.String)
+	if !ok {
+		return newError("module member must be STRING, got %s", index.Type())
+	}
+	member, ok := mod.Member(name.Value)
+	if !ok {
+		return newError("module %s has no member %q", mod.Name, name.Value)
+	}
+	return member
+}
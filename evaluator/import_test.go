@@ -0,0 +1,166 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/object"
+	"github.com/lindeneg/monkey/parser"
+)
+
+// writeFiles creates the given files, keyed by path
+// relative to a new temporary directory, and returns it
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func testEvalFile(t *testing.T, path string) object.Object {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(lexer.NewLexer(string(data)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return Eval(program, object.NewFileEnvironment(path, nil))
+}
+
+func TestImport(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.monkey": `
+let lib = import("lib/math.monkey");
+let again = import("lib/" + "math.monkey");
+[lib["double"](lib["base"]), lib["quad"](1), again["loads"]];
+`,
+		"lib/math.monkey": `
+let helpers = import("helpers.monkey");
+let base = 5;
+let double = fn(x) { helpers["twice"](x) };
+let quad = fn(x) { double(double(x)) };
+let loads = helpers["loads"] + 1;
+`,
+		"lib/helpers.monkey": `
+let loads = 0;
+let twice = fn(x) { x * 2 };
+`,
+	})
+	evaluated := testEvalFile(t, filepath.Join(dir, "main.monkey"))
+	testArrayObject(t, evaluated, []object.Object{
+		&object.Integer{Value: 10},
+		&object.Integer{Value: 4},
+		&object.Integer{Value: 1},
+	})
+}
+
+func TestImportCachesModules(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.monkey": `import("a.monkey") == import("./a.monkey")`,
+		"a.monkey":    `let x = 1;`,
+	})
+	testBooleanObject(t, testEvalFile(t, filepath.Join(dir, "main.monkey")), true)
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.monkey":       `let b = import("b.monkey");`,
+		"b.monkey":       `let a = import("a.monkey");`,
+		"bad.monkey":     `let = 5;`,
+		"failing.monkey": `let x = 1 + true;`,
+		"lib.monkey":     `let x = 1;`,
+		"main.monkey":    `import("a.monkey")`,
+	})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import("a.monkey")`, "import cycle: " + strings.Join([]string{
+			filepath.Join(dir, "a.monkey"),
+			filepath.Join(dir, "b.monkey"),
+			filepath.Join(dir, "a.monkey"),
+		}, " -> ")},
+		{`import("missing.monkey")`, `cannot import "missing.monkey": open ` +
+			filepath.Join(dir, "missing.monkey") + ": no such file or directory"},
+		{`import("bad.monkey")`, `cannot import "bad.monkey": ` +
+			"expected next token to be IDENT, got = instead; " +
+			"l:1|c:5 -> no prefix parse function for =|= found"},
+		{`import("failing.monkey")`, "type mismatch: INTEGER + BOOLEAN"},
+		{`import(1)`, "argument to `import` must be STRING, got INTEGER"},
+		{`import("lib.monkey")["y"]`, `module lib.monkey has no member "y"`},
+		{`import("lib.monkey")[1]`, "module member must be STRING, got INTEGER"},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		env := object.NewFileEnvironment(filepath.Join(dir, "main.monkey"), nil)
+		evaluated := Eval(program, env)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned. got=%T(%+v)",
+				tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expected=%q, got=%q",
+				tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestImportErrorFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.monkey": "let lib = import(\"lib.monkey\");\n" +
+			"let g = fn() { lib[\"f\"]() };\n" +
+			"g();\n",
+		"lib.monkey":     "let f = fn() { h() };\nlet h = fn() {\n  1 + true\n};\n",
+		"top.monkey":     "import(\"failing.monkey\")",
+		"failing.monkey": "let x = 1;\n\nlet y = x + true;",
+	})
+	lib := filepath.Join(dir, "lib.monkey")
+	main := filepath.Join(dir, "main.monkey")
+
+	evaluated := testEvalFile(t, main)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	if err.Line != 3 || err.File != lib {
+		t.Errorf("wrong position. expected=3 in %s, got=%d in %s", lib, err.Line, err.File)
+	}
+	expected := []string{"h at line 1 in " + lib, "(lib[f]) at line 2", "g at line 3"}
+	if strings.Join(err.Stack, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("wrong stack. expected=%q, got=%q", expected, err.Stack)
+	}
+
+	p := parser.New(lexer.NewLexer(`try { import("lib.monkey")["f"]() } catch (e) { [e["line"], e["file"], e["stack"]] }`))
+	caught := Eval(p.ParseProgram(), object.NewFileEnvironment(main, nil))
+	want := "[3, " + lib + ", [h at line 1 in " + lib + ", (import(lib.monkey)[f]) at line 1]]"
+	if caught.Inspect() != want {
+		t.Errorf("wrong caught error. expected=%q, got=%q", want, caught.Inspect())
+	}
+
+	evaluated = testEvalFile(t, filepath.Join(dir, "top.monkey"))
+	err, ok = evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	if failing := filepath.Join(dir, "failing.monkey"); err.Line != 3 || err.File != failing {
+		t.Errorf("wrong position. expected=3 in %s, got=%d in %s", failing, err.Line, err.File)
+	}
+}
@@ -0,0 +1,177 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/lindeneg/monkey/ast"
+	"github.com/lindeneg/monkey/object"
+)
+
+// evalMatchExpression evaluates the body of the first arm matching
+// the subject. Bindings made by the pattern are visible to the guard
+// and the body only. It is an error for no arm to match, an empty
+// body results in NULL.
+func evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(node.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+	for _, arm := range node.Arms {
+		armEnv := object.NewEnclosedEnvironment(env)
+		mismatch, err := bindPattern(arm.Pattern, subject, armEnv)
+		if err != nil {
+			return err
+		}
+		if mismatch != "" {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isError(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+		if result := Eval(arm.Body, armEnv); result != nil {
+			return result
+		}
+		return NULL
+	}
+	return newError("no match arm for %s", subject.Inspect())
+}
+
+// evalDestructuringLet binds the identifiers of the pattern of
+// a let statement, failing if the value does not match. Nothing
+// is bound in env unless the whole pattern matches.
+func evalDestructuringLet(node *ast.LetStatement, val object.Object, env *object.Environment) object.Object {
+	letEnv := object.NewEnclosedEnvironment(env)
+	mismatch, err := bindPattern(node.Pattern, val, letEnv)
+	if err != nil {
+		return err
+	}
+	if mismatch != "" {
+		return newError("cannot destructure %s: %s", node.Pattern.String(), mismatch)
+	}
+	env.SetAll(letEnv)
+	return nil
+}
+
+// bindPattern matches val against pattern, binding the identifiers
+// of the pattern in env. If val does not match, mismatch describes
+// why; bindings made before the mismatch was found are kept, so
+// callers should bind into an environment they can discard. An
+// error is only returned if evaluating a default fails.
+func bindPattern(pattern ast.Expression, val object.Object, env *object.Environment) (mismatch string, err *object.Error) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, val)
+		}
+		return "", nil
+	case *ast.DefaultPattern:
+		return bindPattern(pattern.Pattern, val, env)
+	case *ast.ArrayPattern:
+		arr, ok := val.(*object.Array)
+		if !ok {
+			return fmt.Sprintf("expected ARRAY, got %s", val.Type()), nil
+		}
+		n := len(pattern.Elements)
+		if len(arr.Elements) > n && pattern.Rest == nil {
+			return fmt.Sprintf("expected ARRAY of length %d, got %d", n, len(arr.Elements)), nil
+		}
+		for i, el := range pattern.Elements {
+			if i < len(arr.Elements) {
+				mismatch, err = bindPattern(el, arr.Elements[i], env)
+			} else if def, ok := el.(*ast.DefaultPattern); ok {
+				mismatch, err = bindDefault(def, env)
+			} else {
+				mismatch = fmt.Sprintf("missing element %d", i)
+			}
+			if mismatch != "" || err != nil {
+				return mismatch, err
+			}
+		}
+		if pattern.Rest != nil && pattern.Rest.Value != "_" {
+			rest := []object.Object{}
+			if len(arr.Elements) > n {
+				rest = append(rest, arr.Elements[n:]...)
+			}
+			env.Set(pattern.Rest.Value, &object.Array{Elements: rest})
+		}
+		return "", nil
+	case *ast.HashPattern:
+		hash, ok := val.(*object.Hash)
+		if !ok {
+			return fmt.Sprintf("expected HASH, got %s", val.Type()), nil
+		}
+		for _, pair := range pattern.Pairs {
+			key, ok := literalValue(pair.Key).(object.Hashable)
+			if !ok {
+				return fmt.Sprintf("invalid pattern %s", pair.Key.String()), nil
+			}
+			if value, ok := hash.Get(key); ok {
+				mismatch, err = bindPattern(pair.Value, value, env)
+			} else if def, ok := pair.Value.(*ast.DefaultPattern); ok {
+				mismatch, err = bindDefault(def, env)
+			} else {
+				mismatch = fmt.Sprintf("missing key %s", pair.Key.String())
+			}
+			if mismatch != "" || err != nil {
+				return mismatch, err
+			}
+		}
+		return "", nil
+	default:
+		want := literalValue(pattern)
+		if want == nil {
+			return fmt.Sprintf("invalid pattern %s", pattern.String()), nil
+		}
+		if !literalEqual(want, val) {
+			return fmt.Sprintf("expected %s, got %s", want.Inspect(), val.Inspect()), nil
+		}
+		return "", nil
+	}
+}
+
+// bindDefault matches the default of def, evaluated
+// in env, against its pattern for a missing element
+func bindDefault(def *ast.DefaultPattern, env *object.Environment) (string, *object.Error) {
+	val := Eval(def.Default, env)
+	if err, ok := val.(*object.Error); ok {
+		return "", err
+	}
+	return bindPattern(def.Pattern, val, env)
+}
+
+// literalValue returns the value of a literal pattern,
+// or nil if pattern is not a literal
+func literalValue(pattern ast.Expression) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: pattern.Value}
+	case *ast.PrefixExpression:
+		if integer, ok := pattern.Right.(*ast.IntegerLiteral); ok && pattern.Operator == "-" {
+			return &object.Integer{Value: -integer.Value}
+		}
+	case *ast.StringLiteral:
+		return &object.String{Value: pattern.Value}
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(pattern.Value)
+	}
+	return nil
+}
+
+func literalEqual(a, b object.Object) bool {
+	switch a := a.(type) {
+	case *object.Integer:
+		b, ok := b.(*object.Integer)
+		return ok && a.Value == b.Value
+	case *object.String:
+		b, ok := b.(*object.String)
+		return ok && a.Value == b.Value
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,41 @@
+package evaluator
+
+import "testing"
+
+func TestMatchExpressions(t *testing.T) {
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{`match (1) { 1 => "one", 2 => "two" }`, "one"},
+		{`match (2) { 1 => "one", 2 => "two" }`, "two"},
+		{`match (-3) { 3 => "pos", -3 => "neg" }`, "neg"},
+		{`match ("x") { "y" => 1, "x" => 2 }`, "2"},
+		{`match (true) { false => 0, true => 1 }`, "1"},
+		{`match (1) { "1" => "string", true => "bool", _ => "other" }`, "other"},
+		{`match (5) { n => n * 2 }`, "10"},
+		{`match (1) { 1 => {} }`, "null"},
+		{`str(match (1) { _ => {} })`, "null"},
+		{`match ([1, 2]) { [a] => a, [a, b] => a + b, _ => 0 }`, "3"},
+		{`match ([1, [2, 3]]) { [a, [_, c]] => a + c }`, "4"},
+		{`match ([]) { [] => "empty", _ => "other" }`, "empty"},
+		{`match ([1, 2]) { [1, x] => x, _ => 0 }`, "2"},
+		{`match ([3, 2]) { [1, x] => x, _ => 0 }`, "0"},
+		{`match ({"kind": "circle", "r": 2}) { {"kind": "square", "side": s} => s * s, {"kind": "circle", "r": r} => 3 * r * r }`, "12"},
+		{`match ({"a": 1}) { {"b": b} => b, {} => "any hash" }`, "any hash"},
+		{`match ({1: [true]}) { {1: [flag]} => flag }`, "true"},
+		{`match (7) { n if n < 5 => "small", n if n < 10 => "medium", _ => "large" }`, "medium"},
+		{`match (7) { n => { let m = n + 1; m * 2 } }`, "16"},
+		{`match ([1, 2]) { [a, b] if a > b => "desc", [a, b] => { "asc" } }`, "asc"},
+		{`let x = 1; match (5) { x => x }; x`, "1"},
+		{`let f = fn(v) { match (v) { 0 => { return "zero"; } _ => "other" }; "after" }; [f(0), f(1)]`, "[zero, after]"},
+		{`match ([1, 2, 3]) { [a] => a, [a, ...rest] => rest }`, "[2, 3]"},
+		{`match ([1]) { [a, b = 5] => a + b }`, "6"},
+		{`match ({"a": 1}) { {"a": a, "b": b = 2} => a + b }`, "3"},
+		{`match ([1]) { [a, b = 1 + true] => a }`, "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{`match (3) { 1 => "one", 2 => "two" }`, "ERROR: no match arm for 3"},
+		{`match (1 + true) { _ => 1 }`, "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{`match (1) { n if n + true => 1 }`, "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{`match (1) { _ => ({"a": 1}) }["a"]`, "1"},
+	})
+}
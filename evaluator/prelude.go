@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/object"
+	"github.com/lindeneg/monkey/parser"
+)
+
+// BundledPrelude is the source of the default prelude, a set of
+// helpers written in Monkey that every program can use
+//
+//go:embed prelude.monkey
+var BundledPrelude string
+
+// preludeFile is the file name of the environment the prelude is
+// evaluated in. Identifiers the prelude itself does not define are
+// never looked up in the prelude, which is still being loaded.
+const preludeFile = "<prelude>"
+
+var prelude atomic.Pointer[lazyPrelude]
+
+func init() {
+	SetPrelude(BundledPrelude)
+}
+
+// SetPrelude replaces the prelude with src. Hosts can extend the
+// bundled one by passing BundledPrelude followed by their own
+// definitions, or disable the prelude by passing an empty string.
+func SetPrelude(src string) {
+	prelude.Store(&lazyPrelude{src: src})
+}
+
+// lazyPrelude is only evaluated once a program uses a name that
+// is neither defined by it nor a builtin. It is safe to look up
+// names from several goroutines at once.
+type lazyPrelude struct {
+	src  string
+	once sync.Once
+	env  *object.Environment
+	err  string
+}
+
+// lookup returns the prelude binding name as seen from env or,
+// if the prelude could not be evaluated, an error
+func (p *lazyPrelude) lookup(name string, env *object.Environment) (object.Object, bool) {
+	if p.src == "" || env.File() == preludeFile {
+		return nil, false
+	}
+	p.once.Do(p.load)
+	if p.err != "" {
+		// a new error every time, as evaluating it sets its line and stack
+		return newError("prelude: %s", p.err), true
+	}
+	return p.env.Get(name)
+}
+
+func (p *lazyPrelude) load() {
+	l := lexer.NewLexer(p.src)
+	ps := parser.New(l)
+	program := ps.ParseProgram()
+	if len(ps.Errors()) > 0 {
+		p.err = strings.Join(ps.Errors(), "; ")
+		return
+	}
+	env := object.NewFileEnvironment(preludeFile, nil)
+	if result := Eval(program, env); isError(result) {
+		p.err = result.(*object.Error).Message
+		return
+	}
+	p.env = env
+}
@@ -0,0 +1,167 @@
+package evaluator
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/object"
+	"github.com/lindeneg/monkey/parser"
+)
+
+func TestBundledPrelude(t *testing.T) {
+	p := parser.New(lexer.NewLexer(BundledPrelude))
+	p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("prelude has parser errors: %v", p.Errors())
+	}
+}
+
+func TestPrelude(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"identity(5)", "5"},
+		{"constant(1)(2)", "1"},
+		{"compose(fn(x) { x + 1 }, fn(x) { x * 2 })(5)", "11"},
+		{"pipe(5, [fn(x) { x + 1 }, fn(x) { x * 2 }])", "12"},
+		{"partial(fn(a, b) { a - b }, 10)(3)", "7"},
+		{"flip(fn(a, b) { a - b })(10, 3)", "-7"},
+		{"each([1, 2], fn(x) { x })", "[1, 2]"},
+		{"times(3, fn(i) { i * i })", "[0, 1, 4]"},
+		{"sum([1, 2, 3])", "6"},
+		{"product([2, 3, 4])", "24"},
+		{"count([1, 2, 3], fn(x) { x > 1 })", "2"},
+		{"take([1, 2, 3], 2)", "[1, 2]"},
+		{"drop([1, 2, 3], 2)", "[3]"},
+		{"uniq([1, 2, 1, 3, 2])", "[1, 2, 3]"},
+		{`group_by(["a", "bb", "c"], len)`, "{1: [a, c], 2: [bb]}"},
+		{`capitalize("hello")`, "Hello"},
+		{`capitalize("")`, ""},
+		{`words("  a  b c ")`, "[a, b, c]"},
+		{`pad_left("7", 3, "0")`, "007"},
+		{`pad_right("ab", 4, ".")`, "ab.."},
+		{`pad_left("abcd", 2, "0")`, "abcd"},
+		{`unwrap(int("3"))`, "3"},
+		{`unwrap(int("x"))`, `ERROR: could not parse "x" as INTEGER`},
+		{`unwrap_or(int("x"), 0)`, "0"},
+		{`unwrap_or(int("3"), 0)`, "3"},
+		{"assert(1 < 2, \"order\")", "true"},
+		{"assert(1 > 2, \"order\")", "ERROR: assertion failed: order"},
+		{"assert_eq([1, 2], [1, 2])", "true"},
+		{`assert_eq(1, "1")`, "ERROR: assertion failed: expected 1 (STRING), got 1 (INTEGER)"},
+		{`assert_eq([1], "[1]")`, "ERROR: assertion failed: expected [1] (STRING), got [1] (ARRAY)"},
+		{`assert_eq("a", "a")`, "true"},
+		{"assert_eq(sum([1, 2]), 4)", "ERROR: assertion failed: expected 4, got 3"},
+	}
+	testInspect(t, tests)
+}
+
+func TestPreludeErrorLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1;\ntry { unwrap(error(\"x\")) } catch (e) { [e[\"line\"], e[\"stack\"]] }", "[2, [unwrap at line 2]]"},
+		{"try {\n  sum([1, true])\n} catch (e) { e[\"line\"] }", "2"},
+		{"try {\n  each([1], fn(v) {\n    v + true\n  })\n} catch (e) { e[\"line\"] }", "3"},
+	}
+	testInspect(t, tests)
+
+	err, ok := testEval("let x = 1;\n\nassert(false, \"no\")").(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error")
+	}
+	if err.Line != 3 {
+		t.Errorf("wrong line. expected=3, got=%d", err.Line)
+	}
+	if len(err.Stack) != 1 || err.Stack[0] != "assert at line 3" {
+		t.Errorf("wrong stack, got %q", err.Stack)
+	}
+}
+
+func TestPreludeShadowing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let sum = fn(arr) { 42 }; sum([1, 2])", "42"},
+		{"let identity = 5; identity", "5"},
+		{"undefined_name", "ERROR: identifier not found: undefined_name"},
+	}
+	testInspect(t, tests)
+}
+
+func TestSetPrelude(t *testing.T) {
+	defer SetPrelude(BundledPrelude)
+
+	SetPrelude(BundledPrelude + "\nlet answer = 42;")
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{"answer", "42"},
+		{"sum([answer, 1])", "43"},
+	})
+
+	SetPrelude("")
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{"sum([1])", "ERROR: identifier not found: sum"},
+	})
+
+	SetPrelude("let = 1;")
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{"sum([1])", "ERROR: prelude: expected next token to be IDENT, got = instead; " +
+			"l:1|c:5 -> no prefix parse function for =|= found"},
+		{"let x = 1; x", "1"},
+	})
+
+	SetPrelude("let broken = undefined_name;")
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{"broken", "ERROR: prelude: identifier not found: undefined_name"},
+	})
+}
+
+func TestPreludeConcurrentLoad(t *testing.T) {
+	defer SetPrelude(BundledPrelude)
+	SetPrelude(BundledPrelude)
+
+	var wg sync.WaitGroup
+	results := make([]object.Object, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = testEval("sum([1, 2])")
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		testIntegerObject(t, result, 3)
+	}
+}
+
+func TestPreludeErrorIsFresh(t *testing.T) {
+	defer SetPrelude(BundledPrelude)
+	SetPrelude("let broken = undefined_name;")
+
+	for i := 0; i < 2; i++ {
+		err, ok := testEval("let f = fn() { broken }; f()").(*object.Error)
+		if !ok {
+			t.Fatalf("object is not Error")
+		}
+		if len(err.Stack) != 1 {
+			t.Errorf("wrong stack. expected 1 frame, got=%v", err.Stack)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/lindeneg/monkey/ast"
+	"github.com/lindeneg/monkey/object"
+)
+
+// evalThrowStatement raises the value thrown as an error. Strings
+// become the message, as do error values and the "message" of a
+// hash. The value itself is kept so that a catch can get it back.
+func evalThrowStatement(node *ast.ThrowStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+	message := val.Inspect()
+	switch val := val.(type) {
+	case *object.String:
+		message = val.Value
+	case *object.ErrorValue:
+		message = val.Message
+	case *object.Hash:
+		if msg, ok := val.Get(&object.String{Value: "message"}); ok {
+			if str, ok := msg.(*object.String); ok {
+				message = str.Value
+			}
+		}
+	}
+	return &object.Error{Message: message, Value: val}
+}
+
+// evalTryExpression evaluates the try branch and, should it fail,
+// the catch branch with the error bound to its parameter. The
+// finally branch is always evaluated last. Its result is discarded
+// unless it fails or returns, in which case it replaces the result
+// of the other branches. An empty branch results in NULL.
+func evalTryExpression(node *ast.TryExpression, env *object.Environment) object.Object {
+	result := Eval(node.Block, env)
+	if err, ok := result.(*object.Error); ok && node.Catch != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		catchEnv.Set(node.Param.Value, caughtError(err))
+		result = Eval(node.Catch, catchEnv)
+	}
+	if node.Finally != nil {
+		final := Eval(node.Finally, env)
+		if final != nil {
+			if rt := final.Type(); rt == object.ERROR_OBJ || rt == object.RETURN_VALUE_OBJ {
+				return final
+			}
+		}
+	}
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+// caughtError turns err into the hash bound by a catch
+func caughtError(err *object.Error) *object.Hash {
+	value := err.Value
+	if value == nil {
+		value = NULL
+	}
+	hash := object.NewHash()
+	hash.Set(&object.String{Value: "message"}, &object.String{Value: err.Message})
+	hash.Set(&object.String{Value: "line"}, &object.Integer{Value: int64(err.Line)})
+	hash.Set(&object.String{Value: "file"}, &object.String{Value: err.File})
+	hash.Set(&object.String{Value: "stack"}, stringArray(err.Stack))
+	hash.Set(&object.String{Value: "value"}, value)
+	return hash
+}
+
+// callFrame describes call, made in env, for the stack of an error
+// raised in it. Calls made by the prelude or by an imported module
+// are marked with their file.
+func callFrame(call *ast.CallExpression, env *object.Environment) string {
+	name := call.Function.String()
+	if _, ok := call.Function.(*ast.FunctionLiteral); ok {
+		name = "fn"
+	}
+	if file := env.File(); file == preludeFile || file != env.Modules().Entry() {
+		return fmt.Sprintf("%s at line %d in %s", name, call.Token.Line, file)
+	}
+	return fmt.Sprintf("%s at line %d", name, call.Token.Line)
+}
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/lindeneg/monkey/object"
+)
+
+func TestTryExpressions(t *testing.T) {
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{`try { 1 } catch (e) { 2 }`, "1"},
+		{`try {} catch (e) { 1 }`, "null"},
+		{`try { throw "a"; } catch (e) {}`, "null"},
+		{`str(try {} finally { 1 })`, "null"},
+		{`try { 1 + true } catch (e) { e["message"] }`, "type mismatch: INTEGER + BOOLEAN"},
+		{`try { len(1) } catch (e) { e["message"] }`, "argument to `len` not supported, got INTEGER"},
+		{`try { 1 / 0 } catch (e) { e["message"] }`, "division by zero"},
+		{`let x = 0; 10 / x`, "ERROR: division by zero"},
+		{`try { throw "boom"; } catch (e) { e["message"] }`, "boom"},
+		{`try { throw "boom"; } catch (e) { e["value"] }`, "boom"},
+		{`try { 1 + true } catch (e) { e["value"] }`, "null"},
+		{`try { throw 42; } catch (e) { [e["message"], e["value"]] }`, "[42, 42]"},
+		{`try { throw {"message": "bad", "code": 7}; } catch (e) { [e["message"], e["value"]["code"]] }`, "[bad, 7]"},
+		{`let x = 0; try { x } finally { let x = 5; }; x`, "5"},
+		{`let x = 0; try { throw "a"; } catch (e) { 1 } finally { let x = 2; }; x`, "2"},
+		{`try { throw "a"; } catch (e) { 1 } finally { 2 }`, "1"},
+		{`try { throw "a"; } finally { 2 }`, "ERROR: a"},
+		{`try { 1 } finally { throw "b"; }`, "ERROR: b"},
+		{`try { try { throw "a"; } catch (e) { throw e["message"] + "!"; } } catch (e) { e["message"] }`, "a!"},
+		{`let f = fn() { try { return 1; } catch (e) { 2 }; 3 }; f()`, "1"},
+		{`let f = fn() { try { 1 } finally { return 2; }; 3 }; f()`, "2"},
+		{`let e = 1; try { throw "a"; } catch (e) { e }; e`, "1"},
+		{`throw "uncaught"; 1`, "ERROR: uncaught"},
+		{`let f = fn() { throw 1 + true; }; try { f() } catch (e) { e["message"] }`, "type mismatch: INTEGER + BOOLEAN"},
+	})
+}
+
+func TestErrorLineAndStack(t *testing.T) {
+	input := `let inner = fn(x) {
+  throw "bad " + x;
+};
+let outer = fn() {
+  inner("input")
+};
+try {
+  outer()
+} catch (e) {
+  [e["line"], e["stack"]]
+}`
+	testInspect(t, []struct {
+		input    string
+		expected string
+	}{
+		{input, "[2, [inner at line 5, outer at line 8]]"},
+		{"1;\n2 + true", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+	})
+
+	err, ok := testEval("1;\n\n2 + true").(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error")
+	}
+	if err.Line != 3 {
+		t.Errorf("wrong line. expected=3, got=%d", err.Line)
+	}
+}
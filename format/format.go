@@ -0,0 +1,534 @@
+// Package format implements canonical formatting of Monkey source.
+//
+// The output is indented with four spaces and puts every statement on
+// its own line terminated by a semicolon. If, try and match expressions
+// are only terminated when the next statement starts with (, [ or -,
+// which would otherwise continue the expression. It keeps at most one
+// blank line between statements and only emits the parentheses that
+// are needed to preserve the meaning of an expression. Comments are
+// kept; those found in the middle of a statement are moved to the
+// line above it.
+//
+// Formatting is idempotent, formatting already formatted source
+// returns it unchanged.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/lindeneg/monkey/ast"
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/parser"
+	"github.com/lindeneg/monkey/token"
+)
+
+const indentation = "    "
+
+// Source formats src, which must be a complete Monkey program
+func Source(src []byte) ([]byte, error) {
+	p := parser.New(lexer.NewLexer(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+
+	// the parser does not keep closing delimiters, so the
+	// source is tokenized again to know where things end
+	l := lexer.NewLexer(string(src))
+	pr := &printer{index: make(map[ast.Position]int)}
+	for {
+		tok := l.NextToken()
+		pr.index[position(tok)] = len(pr.tokens)
+		pr.tokens = append(pr.tokens, tok)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	for _, group := range program.Comments {
+		for _, c := range group.List {
+			pr.comments = append(pr.comments, c.Token)
+		}
+	}
+	pr.used = make([]bool, len(pr.comments))
+
+	pr.statements(program.Statements, -1, len(pr.tokens)-1)
+	if pr.out.Len() > 0 {
+		pr.write("\n")
+	}
+	return pr.out.Bytes(), nil
+}
+
+type printer struct {
+	out    bytes.Buffer
+	indent int
+
+	tokens []token.Token
+	// token index by source position
+	index map[ast.Position]int
+
+	comments []token.Token
+	used     []bool
+}
+
+func position(tok token.Token) ast.Position {
+	return ast.Position{Line: tok.Line, Col: tok.Col}
+}
+
+func before(a, b token.Token) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Col < b.Col
+}
+
+func (p *printer) write(s string) {
+	p.out.WriteString(s)
+}
+
+func (p *printer) newline() {
+	p.write("\n")
+	p.write(strings.Repeat(indentation, p.indent))
+}
+
+// tokenIndex returns the index of the first token of node
+func (p *printer) tokenIndex(node ast.Node) int {
+	return p.index[ast.SpanOf(node).Start]
+}
+
+// closing returns the index of the delimiter closing the one at open
+func (p *printer) closing(open int) int {
+	depth := 0
+	for i := open; i < len(p.tokens); i++ {
+		switch p.tokens[i].Type {
+		case token.LBRACE, token.LBRACKET, token.LPAREN:
+			depth++
+		case token.RBRACE, token.RBRACKET, token.RPAREN:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(p.tokens) - 1
+}
+
+// blockRanges returns the token ranges of all blocks within node
+func (p *printer) blockRanges(node ast.Node) [][2]int {
+	var ranges [][2]int
+	ast.Inspect(node, func(n ast.Node) bool {
+		if b, ok := n.(*ast.BlockStatement); ok && b != nil {
+			open := p.index[position(b.Token)]
+			ranges = append(ranges, [2]int{open, p.closing(open)})
+		}
+		return true
+	})
+	return ranges
+}
+
+// takeComments marks and returns the unused comments that are
+// positioned after token from and before token to, skipping
+// those that fall within any of the given ranges
+func (p *printer) takeComments(from, to int, skip [][2]int) []token.Token {
+	var taken []token.Token
+	for i, c := range p.comments {
+		if p.used[i] {
+			continue
+		}
+		if from >= 0 && !before(p.tokens[from], c) || !before(c, p.tokens[to]) {
+			continue
+		}
+		inside := false
+		for _, r := range skip {
+			if before(p.tokens[r[0]], c) && before(c, p.tokens[r[1]]) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			p.used[i] = true
+			taken = append(taken, c)
+		}
+	}
+	return taken
+}
+
+// hasComments reports whether there are unused comments
+// between the tokens at index from and to
+func (p *printer) hasComments(from, to int) bool {
+	for i, c := range p.comments {
+		if !p.used[i] && before(p.tokens[from], c) && before(c, p.tokens[to]) {
+			return true
+		}
+	}
+	return false
+}
+
+// statements prints a list of statements delimited by the tokens at
+// index open and close, which are a pair of braces or, for the program,
+// -1 and the EOF token. Each statement is preceded by a newline unless
+// it is the first thing printed.
+func (p *printer) statements(list []ast.Statement, open, close int) {
+	prevLine := 0
+	if open >= 0 {
+		prevLine = p.tokens[open].Line
+	}
+	first := true
+	emit := func(line int, print func()) {
+		if p.out.Len() > 0 {
+			if !first && line-prevLine > 1 {
+				p.write("\n")
+			}
+			p.newline()
+		}
+		first = false
+		print()
+	}
+	emitComments := func(comments []token.Token) {
+		for _, c := range comments {
+			emit(c.Line, func() { p.write(c.Literal) })
+			prevLine = c.Line
+		}
+	}
+	// comments moved out of a statement are spaced as the statement is
+	hoistComments := func(comments []token.Token, line int) {
+		for _, c := range comments {
+			emit(line, func() { p.write(c.Literal) })
+			prevLine = line
+		}
+	}
+	// offset just after the closing brace of the previous statement
+	// if it is an if, try or match expression, which is printed
+	// without a semicolon unless the next statement needs one
+	braceEnd := -1
+	for i, s := range list {
+		start := p.tokenIndex(s)
+		end := close - 1
+		if i+1 < len(list) {
+			end = p.tokenIndex(list[i+1]) - 1
+		}
+		emitComments(p.takeComments(open, start, nil))
+		hoistComments(p.takeComments(start, end, p.blockRanges(s)), p.tokens[start].Line)
+		emit(p.tokens[start].Line, func() {
+			offset := p.out.Len()
+			p.statement(s)
+			if braceEnd >= 0 && continuesExpression(p.out.Bytes()[offset]) {
+				p.insert(braceEnd, ";")
+			}
+			braceEnd = -1
+			if endsWithBrace(s) {
+				braceEnd = p.out.Len()
+			}
+		})
+		prevLine = p.tokens[end].Line
+		for _, c := range p.takeComments(end, end+1, nil) {
+			if c.Line == prevLine {
+				p.write(" " + c.Literal)
+			} else {
+				emitComments([]token.Token{c})
+			}
+		}
+		open = end
+	}
+	emitComments(p.takeComments(open, close, nil))
+}
+
+// continuesExpression reports whether a statement starting with c
+// would be parsed as part of an expression statement before it
+func continuesExpression(c byte) bool {
+	return c == '(' || c == '[' || c == '-'
+}
+
+// endsWithBrace reports whether s is printed without a semicolon
+func endsWithBrace(s ast.Statement) bool {
+	stmt, ok := s.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	switch stmt.Expression.(type) {
+	case *ast.IfExpression, *ast.TryExpression, *ast.MatchExpression:
+		return true
+	}
+	return false
+}
+
+// insert inserts s into the output at offset
+func (p *printer) insert(offset int, s string) {
+	rest := append([]byte(s), p.out.Bytes()[offset:]...)
+	p.out.Truncate(offset)
+	p.out.Write(rest)
+}
+
+func (p *printer) statement(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		if s.Name != nil {
+			p.write("let " + s.Name.Value + " = ")
+		} else {
+			p.write("let ")
+			p.expression(s.Pattern, parser.LOWEST)
+			p.write(" = ")
+		}
+		p.expression(s.Value, parser.LOWEST)
+		p.write(";")
+	case *ast.ReturnStatement:
+		p.write("return ")
+		p.expression(s.ReturnValue, parser.LOWEST)
+		p.write(";")
+	case *ast.ThrowStatement:
+		p.write("throw ")
+		p.expression(s.Value, parser.LOWEST)
+		p.write(";")
+	case *ast.ExpressionStatement:
+		p.expression(s.Expression, parser.LOWEST)
+		if !endsWithBrace(s) {
+			p.write(";")
+		}
+	case *ast.BlockStatement:
+		p.block(s)
+	}
+}
+
+func (p *printer) block(b *ast.BlockStatement) {
+	open := p.index[position(b.Token)]
+	close := p.closing(open)
+	if len(b.Statements) == 0 && !p.hasComments(open, close) {
+		p.write("{}")
+		return
+	}
+	p.write("{")
+	p.indent++
+	p.statements(b.Statements, open, close)
+	p.indent--
+	p.newline()
+	p.write("}")
+}
+
+// precedence returns how tightly e binds to its operands
+func precedence(e ast.Expression) int {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(token.TokenType(e.Operator))
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.CallExpression:
+		return parser.CALL
+	case *ast.IndexExpression, *ast.SliceExpression:
+		return parser.INDEX
+	default:
+		return parser.INDEX + 1
+	}
+}
+
+// expression prints e, wrapped in parentheses if it binds
+// less tightly than the minimum precedence required
+func (p *printer) expression(e ast.Expression, min int) {
+	if precedence(e) < min {
+		p.write("(")
+		defer p.write(")")
+	}
+	switch e := e.(type) {
+	case *ast.Identifier:
+		p.write(e.Value)
+	case *ast.IntegerLiteral:
+		p.write(e.Token.Literal)
+	case *ast.Boolean:
+		p.write(e.Token.Literal)
+	case *ast.StringLiteral:
+		p.write(`"` + e.Value + `"`)
+	case *ast.PrefixExpression:
+		p.write(e.Operator)
+		p.expression(e.Right, parser.PREFIX)
+	case *ast.InfixExpression:
+		prec := precedence(e)
+		p.expression(e.Left, prec)
+		p.write(" " + e.Operator + " ")
+		p.expression(e.Right, prec+1)
+	case *ast.IndexExpression:
+		p.expression(e.Left, parser.CALL)
+		p.write("[")
+		p.expression(e.Index, parser.LOWEST)
+		p.write("]")
+	case *ast.SliceExpression:
+		p.expression(e.Left, parser.CALL)
+		p.write("[")
+		if e.Start != nil {
+			p.expression(e.Start, parser.LOWEST)
+		}
+		p.write(":")
+		if e.End != nil {
+			p.expression(e.End, parser.LOWEST)
+		}
+		if e.Step != nil {
+			p.write(":")
+			p.expression(e.Step, parser.LOWEST)
+		}
+		p.write("]")
+	case *ast.CallExpression:
+		p.expression(e.Function, parser.CALL)
+		p.write("(")
+		p.expressionList(e.Arguments)
+		p.write(")")
+	case *ast.ImportExpression:
+		p.write("import(")
+		p.expression(e.Path, parser.LOWEST)
+		p.write(")")
+	case *ast.ArrayLiteral:
+		p.write("[")
+		p.expressionList(e.Elements)
+		p.write("]")
+	case *ast.HashLiteral:
+		p.hash(e)
+	case *ast.FunctionLiteral:
+		p.write("fn(")
+		for i, param := range e.Parameters {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.write(param.Value)
+		}
+		p.write(") ")
+		p.block(e.Body)
+	case *ast.TryExpression:
+		p.write("try ")
+		p.block(e.Block)
+		if e.Catch != nil {
+			p.write(" catch (" + e.Param.Value + ") ")
+			p.block(e.Catch)
+		}
+		if e.Finally != nil {
+			p.write(" finally ")
+			p.block(e.Finally)
+		}
+	case *ast.MatchExpression:
+		p.match(e)
+	case *ast.ArrayPattern:
+		p.write("[")
+		p.expressionList(e.Elements)
+		if e.Rest != nil {
+			if len(e.Elements) > 0 {
+				p.write(", ")
+			}
+			p.write("..." + e.Rest.Value)
+		}
+		p.write("]")
+	case *ast.DefaultPattern:
+		p.expression(e.Pattern, parser.LOWEST)
+		p.write(" = ")
+		p.expression(e.Default, parser.LOWEST)
+	case *ast.HashPattern:
+		p.write("{")
+		for i, pair := range e.Pairs {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expression(pair.Key, parser.LOWEST)
+			p.write(": ")
+			p.expression(pair.Value, parser.LOWEST)
+		}
+		p.write("}")
+	case *ast.IfExpression:
+		p.write("if (")
+		p.expression(e.Condition, parser.LOWEST)
+		p.write(") ")
+		p.block(e.Consequence)
+		if e.Alternative != nil {
+			p.write(" else ")
+			p.block(e.Alternative)
+		}
+	}
+}
+
+func (p *printer) expressionList(list []ast.Expression) {
+	for i, e := range list {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expression(e, parser.LOWEST)
+	}
+}
+
+// match prints each arm on a line of its own, followed by a
+// comma unless its body is a block
+func (p *printer) match(m *ast.MatchExpression) {
+	p.write("match (")
+	p.expression(m.Subject, parser.LOWEST)
+	p.write(") {")
+	p.indent++
+	for _, arm := range m.Arms {
+		p.newline()
+		p.expression(arm.Pattern, parser.LOWEST)
+		if arm.Guard != nil {
+			p.write(" if ")
+			p.expression(arm.Guard, parser.LOWEST)
+		}
+		p.write(" => ")
+		switch body := arm.Body.(type) {
+		case *ast.BlockStatement:
+			p.block(body)
+		case ast.Expression:
+			// without parentheses a leading hash would be read as a block
+			if startsWithHash(body) {
+				p.write("(")
+				p.expression(body, parser.LOWEST)
+				p.write(")")
+			} else {
+				p.expression(body, parser.LOWEST)
+			}
+			p.write(",")
+		}
+	}
+	p.indent--
+	p.newline()
+	p.write("}")
+}
+
+// startsWithHash reports whether the leftmost operand of e
+// is a hash literal
+func startsWithHash(e ast.Expression) bool {
+	for {
+		switch n := e.(type) {
+		case *ast.HashLiteral:
+			return true
+		case *ast.IndexExpression:
+			e = n.Left
+		case *ast.SliceExpression:
+			e = n.Left
+		case *ast.InfixExpression:
+			e = n.Left
+		case *ast.CallExpression:
+			e = n.Function
+		default:
+			return false
+		}
+	}
+}
+
+// hash prints a hash literal on a single line, unless the
+// source had its closing brace on a line of its own
+func (p *printer) hash(h *ast.HashLiteral) {
+	if len(h.Pairs) == 0 {
+		p.write("{}")
+		return
+	}
+	open := p.index[position(h.Token)]
+	multiline := p.tokens[p.closing(open)].Line > h.Token.Line
+	p.write("{")
+	p.indent++
+	for i, pair := range h.Pairs {
+		if multiline {
+			p.newline()
+		} else if i > 0 {
+			p.write(", ")
+		}
+		p.expression(pair.Key, parser.LOWEST)
+		p.write(": ")
+		p.expression(pair.Value, parser.LOWEST)
+		if multiline {
+			p.write(",")
+		}
+	}
+	p.indent--
+	if multiline {
+		p.newline()
+	}
+	p.write("}")
+}
@@ -0,0 +1,126 @@
+package format
+
+import "testing"
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=5", "let x = 5;\n"},
+		{"return  x", "return x;\n"},
+		{"a + b * c", "a + b * c;\n"},
+		{"(a + b) * c", "(a + b) * c;\n"},
+		{"a - (b - c)", "a - (b - c);\n"},
+		{"(a - b) - c", "a - b - c;\n"},
+		{"-(a + b)", "-(a + b);\n"},
+		{"!(true == false)", "!(true == false);\n"},
+		{"(-a)[0]", "(-a)[0];\n"},
+		{"-a[0]", "-a[0];\n"},
+		{"f(x)[0](y)", "f(x)[0](y);\n"},
+		{"a[ 1 : -1 ]", "a[1:-1];\n"},
+		{`let m = import( "lib.monkey" )`, "let m = import(\"lib.monkey\");\n"},
+		{`import("a")["b"]`, "import(\"a\")[\"b\"];\n"},
+		{"a[::(-1)]", "a[::-1];\n"},
+		{`throw  "bad"`, "throw \"bad\";\n"},
+		{
+			"try { f(x) } catch (e) { throw e; } finally { g() }",
+			"try {\n    f(x);\n} catch (e) {\n    throw e;\n} finally {\n    g();\n}\n",
+		},
+		{
+			`match(x){1=>"one",[a,{"k":_}] if a>1=>{a} _=>({"a":1})}`,
+			"match (x) {\n    1 => \"one\",\n    [a, {\"k\": _}] if a > 1 => {\n        a;\n    }\n    _ => ({\"a\": 1}),\n}\n",
+		},
+		{"let r = match (x) { -1 => 0, n => n }", "let r = match (x) {\n    -1 => 0,\n    n => n,\n};\n"},
+		{"let [a,b=1,...rest]=arr", "let [a, b = 1, ...rest] = arr;\n"},
+		{`let {"name":n,"pos":[x,y]}=p`, "let {\"name\": n, \"pos\": [x, y]} = p;\n"},
+		{"let r = try {1} finally {2}", "let r = try {\n    1;\n} finally {\n    2;\n};\n"},
+		{"(a + b)[:2]", "(a + b)[:2];\n"},
+		{`["a",1,  true]`, "[\"a\", 1, true];\n"},
+		{`{"a":1,"b":2}`, "{\"a\": 1, \"b\": 2};\n"},
+		{"{\"a\": 1,\n\"b\": 2\n}", "{\n    \"a\": 1,\n    \"b\": 2,\n};\n"},
+		{"{}", "{};\n"},
+		{"fn(){}", "fn() {};\n"},
+		{"let f = fn(x, y) { x + y }", "let f = fn(x, y) {\n    x + y;\n};\n"},
+		{
+			"if (x < y) { x } else { if (y) { return y; } }",
+			"if (x < y) {\n    x;\n} else {\n    if (y) {\n        return y;\n    }\n}\n",
+		},
+		{"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;", "let a = 1;\n\nlet b = 2;\nlet c = 3;\n"},
+		{"// just a comment", "// just a comment\n"},
+		{"let a = 1; // one\n// two\nlet b = 2;", "let a = 1; // one\n// two\nlet b = 2;\n"},
+		{"let f = fn() {\n  // todo\n};", "let f = fn() {\n    // todo\n};\n"},
+		{
+			"let f = fn(x) { // first\n  x; // second\n\n  // third\n};",
+			"let f = fn(x) {\n    // first\n    x; // second\n\n    // third\n};\n",
+		},
+		{"let h = {\n  // moved\n  \"a\": 1\n};", "// moved\nlet h = {\n    \"a\": 1,\n};\n"},
+		{"if (x) { 1 };\n(1 + 2) * 3;", "if (x) {\n    1;\n};\n(1 + 2) * 3;\n"},
+		{"if (x) { 1 };\n[1, 2];", "if (x) {\n    1;\n};\n[1, 2];\n"},
+		{"match (x) { _ => 1 };\n// note\n-1", "match (x) {\n    _ => 1,\n};\n// note\n-1;\n"},
+		{"if (x) { 1 };\nlet y = 2;", "if (x) {\n    1;\n}\nlet y = 2;\n"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		out, err := Source([]byte(tt.input))
+		if err != nil {
+			t.Errorf("Source(%q) returned error: %s", tt.input, err)
+			continue
+		}
+		if string(out) != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, out)
+		}
+	}
+}
+
+func TestSourceIdempotent(t *testing.T) {
+	inputs := []string{
+		`// STDLIB
+
+let map = fn(arr, f) {
+    let iter = fn(arr, acc) {
+        if (len(arr) == 0) {
+            return acc;
+        } else {
+            return iter(rest(arr), push(acc, f(first(arr))));
+        }
+    };
+    return iter(arr, []);
+};
+
+let lib = {
+    "map": map // trailing
+};
+
+// TEST
+let x = [1, 2, 3, 4];
+let xx = lib["map"](x, fn(x) { return x * 2; });
+println(xx);
+`,
+		"let a = 1; let b = 2; // same line\nlet c = fn(x) { if (x) { 1 } else { 2 } }(a)",
+		"let h = {1: {\"a\": [1, 2]}, true: fn() {}};\n\n\n// end",
+		"if (x) { 1 };\n(1 + 2) * 3;",
+		"if (x) { 1 };\n[1, 2];",
+		`match (x) { _ => ({"a": 1})["a"] }`,
+		`match (x) { 1 => ({"a": 1}), 2 => ({"f": len})["f"]("ab") + 1, _ => ({"a": [1]})["a"][0:1] }`,
+	}
+	for _, input := range inputs {
+		first, err := Source([]byte(input))
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", input, err)
+		}
+		second, err := Source(first)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", first, err)
+		}
+		if string(first) != string(second) {
+			t.Errorf("formatting is not idempotent.\nfirst=%q\nsecond=%q", first, second)
+		}
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	if _, err := Source([]byte("let = 5;")); err == nil {
+		t.Errorf("expected error for invalid source")
+	}
+}
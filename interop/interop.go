@@ -0,0 +1,263 @@
+// Package interop exposes Go functions and values to Monkey scripts.
+//
+// Go functions become builtins that convert their arguments from
+// Monkey objects to the parameter types of the function and their
+// results back, so that host code does not have to type switch on
+// object.Object values. A function may return nothing, a value,
+// an error, or a value and an error; a non-nil error is returned
+// to the script as an *object.Error.
+//
+// Arguments and results are converted with object.ToGoValue and
+// object.FromGo, so struct fields can be renamed with `monkey` tags.
+// Parameters of function type accept Monkey functions, which the Go
+// code can call like any other function, also after returning. An
+// error raised by such a callback is returned as an *evaluator.Error
+// if its Go type returns an error. Otherwise the callback panics with
+// a *CallbackError, which is recovered from while the Go function it
+// was passed to runs. Host code calling a stored callback later must
+// recover the panic itself, or give the callback an error result.
+package interop
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/lindeneg/monkey/evaluator"
+	"github.com/lindeneg/monkey/object"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Func converts fn, which must be a Go function, into a builtin
+func Func(fn interface{}) (*object.Builtin, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("interop: %T is not a function", fn)
+	}
+	return funcValue(v)
+}
+
+// MustFunc is like Func but panics if fn is not a function
+func MustFunc(fn interface{}) *object.Builtin {
+	b, err := Func(fn)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func funcValue(fn reflect.Value) (*object.Builtin, error) {
+	t := fn.Type()
+	switch {
+	case t.NumOut() > 2,
+		t.NumOut() == 2 && t.Out(1) != errorType:
+		return nil, fmt.Errorf("interop: %s must return at most a value and an error", t)
+	}
+	return &object.Builtin{
+		CtxFn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			in, err := arguments(ctx, t, args)
+			if err != nil {
+				return &object.Error{Message: err.Error()}
+			}
+			return call(fn, in)
+		},
+	}, nil
+}
+
+// CallbackError is the panic raised when a callback without an
+// error result fails
+type CallbackError struct {
+	Err *evaluator.Error
+}
+
+func (e *CallbackError) Error() string {
+	return "interop: callback failed: " + e.Err.Error()
+}
+
+// call calls fn with in and converts its results. If a callback
+// passed to fn fails and cannot return an error, the error it
+// raised is returned instead.
+func call(fn reflect.Value, in []reflect.Value) (result object.Object) {
+	defer func() {
+		if r := recover(); r != nil {
+			failed, ok := r.(*CallbackError)
+			if !ok {
+				panic(r)
+			}
+			result = failed.Err.Object
+		}
+	}()
+	return results(fn.Call(in))
+}
+
+// arguments converts args to the parameters of a function of type t
+func arguments(ctx object.CallContext, t reflect.Type, args []object.Object) ([]reflect.Value, error) {
+	n := t.NumIn()
+	if t.IsVariadic() {
+		if len(args) < n-1 {
+			return nil, fmt.Errorf("wrong number of arguments. got=%d, want at least %d",
+				len(args), n-1)
+		}
+	} else if len(args) != n {
+		return nil, fmt.Errorf("wrong number of arguments. got=%d, want=%d",
+			len(args), n)
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var pt reflect.Type
+		if t.IsVariadic() && i >= n-1 {
+			pt = t.In(n - 1).Elem()
+		} else {
+			pt = t.In(i)
+		}
+		if pt.Kind() == reflect.Func {
+			v, err := callback(ctx, arg, pt)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d: %s", i+1, err)
+			}
+			in[i] = v
+			continue
+		}
+		v := reflect.New(pt)
+		if err := object.ToGoValue(arg, v.Interface()); err != nil {
+			return nil, fmt.Errorf("argument %d: %s", i+1, err)
+		}
+		in[i] = v.Elem()
+	}
+	return in, nil
+}
+
+// callback converts fn, a Monkey function, to a Go function of type
+// t calling it through ctx. Errors raised by fn are returned if t
+// returns an error as its last result and cause a *CallbackError
+// panic otherwise, which is recovered from by call if fn was passed
+// to a bound Go function.
+func callback(ctx object.CallContext, fn object.Object, t reflect.Type) (reflect.Value, error) {
+	switch fn.(type) {
+	case *object.Function, *object.Builtin:
+	default:
+		return reflect.Value{}, fmt.Errorf("must be FUNCTION, got %s", fn.Type())
+	}
+	returnsErr := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+	values := t.NumOut()
+	if returnsErr {
+		values--
+	}
+	if values > 1 {
+		return reflect.Value{}, fmt.Errorf("unsupported Go type %s", t)
+	}
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(t.Out(i))
+		}
+		fail := func(errObj *object.Error) []reflect.Value {
+			callErr := &evaluator.Error{Object: errObj}
+			if !returnsErr {
+				panic(&CallbackError{Err: callErr})
+			}
+			var err error = callErr
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
+			return out
+		}
+		args := make([]object.Object, len(in))
+		for i, v := range in {
+			arg, err := object.FromGo(v.Interface())
+			if err != nil {
+				return fail(&object.Error{Message: fmt.Sprintf("argument %d: %s", i+1, err)})
+			}
+			args[i] = arg
+		}
+		result := ctx.Call(fn, args...)
+		if errObj, ok := result.(*object.Error); ok {
+			return fail(errObj)
+		}
+		if values == 1 {
+			v := reflect.New(t.Out(0))
+			if err := object.ToGoValue(result, v.Interface()); err != nil {
+				return fail(&object.Error{Message: "result " + err.Error()})
+			}
+			out[0] = v.Elem()
+		}
+		return out
+	}), nil
+}
+
+// results converts what a function returned to a single object
+func results(out []reflect.Value) object.Object {
+	if n := len(out); n > 0 && out[n-1].Type() == errorType {
+		if !out[n-1].IsNil() {
+			return &object.Error{Message: out[n-1].Interface().(error).Error()}
+		}
+		out = out[:n-1]
+	}
+	if len(out) == 0 {
+		return evaluator.NULL
+	}
+	if out[0].Kind() == reflect.Func && !out[0].IsNil() {
+		b, err := funcValue(out[0])
+		if err != nil {
+			return &object.Error{Message: "result " + err.Error()}
+		}
+		return b
+	}
+	obj, err := object.FromGo(out[0].Interface())
+	if err != nil {
+		return &object.Error{Message: "result " + err.Error()}
+	}
+	return obj
+}
+
+// Struct converts a struct, or a pointer to one, into a hash holding
+// its exported fields followed by its exported methods as builtins.
+// The fields are copied, methods changing them through a pointer
+// receiver are not reflected in the hash.
+func Struct(v interface{}) (*object.Hash, error) {
+	rv := reflect.ValueOf(v)
+	s := rv
+	if s.Kind() == reflect.Pointer {
+		s = s.Elem()
+	}
+	if s.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("interop: %T is not a struct", v)
+	}
+	obj, err := object.FromGo(s.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("interop: %w", err)
+	}
+	hash := obj.(*object.Hash)
+	for i := 0; i < rv.NumMethod(); i++ {
+		method, err := funcValue(rv.Method(i))
+		if err != nil {
+			return nil, fmt.Errorf("interop: method %s: %w", rv.Type().Method(i).Name, err)
+		}
+		hash.Set(&object.String{Value: rv.Type().Method(i).Name}, method)
+	}
+	return hash, nil
+}
+
+// Define binds name in env to v converted to an object.
+// Functions become builtins and structs hashes with
+// their methods, see Func and Struct.
+func Define(env *object.Environment, name string, v interface{}) error {
+	var obj object.Object
+	var err error
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Kind() == reflect.Struct,
+		rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Struct:
+		obj, err = Struct(v)
+	case rv.Kind() == reflect.Func:
+		obj, err = Func(v)
+	default:
+		obj, err = object.FromGo(v)
+		if err != nil {
+			err = fmt.Errorf("interop: %w", err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	env.Set(name, obj)
+	return nil
+}
@@ -0,0 +1,252 @@
+package interop
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lindeneg/monkey/evaluator"
+	"github.com/lindeneg/monkey/lexer"
+	"github.com/lindeneg/monkey/object"
+	"github.com/lindeneg/monkey/parser"
+)
+
+type point struct {
+	X, Y   int
+	hidden string
+}
+
+func (p point) Sum() int { return p.X + p.Y }
+
+func (p *point) Scale(n int) { p.X *= n; p.Y *= n }
+
+type user struct {
+	Name   string
+	Tags   []string
+	Admin  bool
+	Boss   *user
+	Secret string `monkey:"-"`
+}
+
+type config struct {
+	Host string `monkey:"host"`
+	Port int    `monkey:"port,omitempty"`
+}
+
+func testEval(t *testing.T, env *object.Environment, input string) object.Object {
+	t.Helper()
+	p := parser.New(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return evaluator.Eval(program, env)
+}
+
+func TestFunc(t *testing.T) {
+	env := object.NewEnvironment()
+	define := func(name string, v interface{}) {
+		if err := Define(env, name, v); err != nil {
+			t.Fatalf("Define(%s): %s", name, err)
+		}
+	}
+	define("repeat", strings.Repeat)
+	define("check", func(s string, n int) (bool, error) {
+		if n < 0 {
+			return false, errors.New("n must not be negative")
+		}
+		return len(s) == n, nil
+	})
+	define("sum", func(ns ...int) int {
+		total := 0
+		for _, n := range ns {
+			total += n
+		}
+		return total
+	})
+	define("small", func(n int8) int8 { return n })
+	define("nothing", func() {})
+	define("keys", func(m map[string]int) []string {
+		var keys []string
+		for k := range m {
+			keys = append(keys, k)
+		}
+		return keys
+	})
+	define("counts", func() map[string]int { return map[string]int{"b": 2, "a": 1, "c": 3} })
+	define("describe", func(v interface{}) string {
+		switch v.(type) {
+		case int64:
+			return "int"
+		case []interface{}:
+			return "list"
+		case map[string]interface{}, map[interface{}]interface{}:
+			return "map"
+		case nil:
+			return "nil"
+		default:
+			return "other"
+		}
+	})
+	define("inspect", func(obj object.Object) string { return obj.Inspect() })
+	define("user", func(name string) *user {
+		return &user{Name: name, Tags: []string{"x"}, Boss: &user{Name: "boss"}}
+	})
+	define("greet", func(u user) string { return "hi " + u.Name })
+	define("answer", 42)
+	define("address", func(c config) string { return fmt.Sprintf("%s:%d", c.Host, c.Port) })
+	define("defaults", func() config { return config{Host: "localhost", Port: 80} })
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`repeat("ab", 2)`, "abab"},
+		{`check("abc", 3)`, "true"},
+		{`check("abc", 2)`, "false"},
+		{`check("abc", -1)`, "ERROR: n must not be negative"},
+		{`check("abc")`, "ERROR: wrong number of arguments. got=1, want=2"},
+		{`check(1, 2)`, "ERROR: argument 1: must be STRING, got INTEGER"},
+		{`sum()`, "0"},
+		{`sum(1, 2, 3)`, "6"},
+		{`sum(1, "2")`, "ERROR: argument 2: must be INTEGER, got STRING"},
+		{`small(127)`, "127"},
+		{`small(128)`, "ERROR: argument 1: 128 overflows int8"},
+		{`nothing()`, "null"},
+		{`keys({"a": 1})`, "[a]"},
+		{`keys({"a": "1"})`, "ERROR: argument 1: value of a: must be INTEGER, got STRING"},
+		{`counts()`, "{a: 1, b: 2, c: 3}"},
+		{`describe(1)`, "int"},
+		{`describe([1])`, "list"},
+		{`describe({"a": 1})`, "map"},
+		{`describe(first([]))`, "nil"},
+		{`describe(fn() {})`, "ERROR: argument 1: cannot convert FUNCTION to a Go value"},
+		{`inspect(fn(x) { x })`, "fn(x) {\nx\n}"},
+		{`user("ann")`, "{Name: ann, Tags: [x], Admin: false, Boss: {Name: boss, Tags: null, Admin: false, Boss: null}}"},
+		{`greet({"Name": "bob"})`, "hi bob"},
+		{`greet({"Name": 1})`, "ERROR: argument 1: field Name: must be STRING, got INTEGER"},
+		{`answer`, "42"},
+		{`address({"host": "example.com", "port": 8080})`, "example.com:8080"},
+		{`defaults()`, "{host: localhost, port: 80}"},
+		{`if (check("a", 1)) { 1 } else { 2 }`, "1"},
+		{`if (check("a", 2)) { 1 } else { 2 }`, "2"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(t, env, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestStruct(t *testing.T) {
+	p := &point{X: 1, Y: 2, hidden: "h"}
+	env := object.NewEnvironment()
+	if err := Define(env, "p", p); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`keys(p)`, "[X, Y, Scale, Sum]"},
+		{`p["X"] + p["Y"]`, "3"},
+		{`p["Sum"]()`, "3"},
+		{`p["Scale"](10); p["Sum"]()`, "30"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(t, env, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+	if p.X != 10 || p.Y != 20 {
+		t.Errorf("Scale did not change the Go value, got %+v", *p)
+	}
+}
+
+func TestFuncErrors(t *testing.T) {
+	if _, err := Func(5); err == nil {
+		t.Errorf("expected error for non-function")
+	}
+	if _, err := Func(func() (int, int) { return 1, 2 }); err == nil {
+		t.Errorf("expected error for function returning two values")
+	}
+	if _, err := Struct(5); err == nil {
+		t.Errorf("expected error for non-struct")
+	}
+	if err := Define(object.NewEnvironment(), "c", make(chan int)); err == nil {
+		t.Errorf("expected error for channel")
+	}
+}
+
+func TestCallback(t *testing.T) {
+	env := object.NewEnvironment()
+	handlers := map[string]func(string) string{}
+	Define(env, "apply_twice", func(f func(int) int, x int) int { return f(f(x)) })
+	Define(env, "try_apply", func(f func(int) (int, error), x int) string {
+		n, err := f(x)
+		if err != nil {
+			return "failed: " + err.Error()
+		}
+		return fmt.Sprint(n)
+	})
+	Define(env, "apply", func(xs []int, f func(int) int) []int {
+		for i, x := range xs {
+			xs[i] = f(x)
+		}
+		return xs
+	})
+	Define(env, "on", func(event string, handler func(string) string) {
+		handlers[event] = handler
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"apply_twice(fn(x) { x * 2 }, 3)", "12"},
+		{"apply_twice(abs, -3)", "3"},
+		{"apply_twice(1, 3)", "ERROR: argument 1: must be FUNCTION, got INTEGER"},
+		{"try_apply(fn(x) { x + 1 }, 1)", "2"},
+		{"try_apply(fn(x) { x + true }, 1)", "failed: type mismatch: INTEGER + BOOLEAN"},
+		{`try_apply(fn(x) { "a" }, 1)`, "failed: result must be INTEGER, got STRING"},
+		{"apply([1, 2], fn(x) { x * 3 })", "[3, 6]"},
+		{`apply([1, 2], fn(x) { x + "a" })`, "ERROR: type mismatch: INTEGER + STRING"},
+		{`apply([1, 2], fn(x) { "a" })`, "ERROR: result must be INTEGER, got STRING"},
+		{`try { apply([1, 2], fn(x) { x + "a" }) } catch (e) { e["message"] }`, "type mismatch: INTEGER + STRING"},
+		{`on("greet", fn(name) { "hello " + name })`, "null"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(t, env, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	handler, ok := handlers["greet"]
+	if !ok {
+		t.Fatalf("handler was not registered")
+	}
+	if got := handler("ann"); got != "hello ann" {
+		t.Errorf("handler returned %q", got)
+	}
+
+	testEval(t, env, `on("fail", fn(name) { throw "no " + name; })`)
+	func() {
+		defer func() {
+			failed, ok := recover().(*CallbackError)
+			if !ok {
+				t.Fatalf("failing handler did not panic with *CallbackError")
+			}
+			if failed.Err.Object.Message != "no ann" {
+				t.Errorf("wrong error message, got %q", failed.Err.Object.Message)
+			}
+			if failed.Error() != "interop: callback failed: no ann" {
+				t.Errorf("wrong panic message, got %q", failed.Error())
+			}
+		}()
+		handlers["fail"]("ann")
+	}()
+}
@@ -1,6 +1,8 @@
 package lexer
 
 import (
+	"strings"
+
 	"github.com/lindeneg/monkey/token"
 )
 
@@ -16,12 +18,14 @@ type Lexer struct {
 	Lines int
 	// current col
 	Col int
+	// comments read so far, in source order
+	comments []token.Token
 }
 
 // Creates a new lexer and reads the
 // first character of the input string
 func NewLexer(input string) *Lexer {
-	l := &Lexer{input: input, Lines: 1, Col: 1}
+	l := &Lexer{input: input, Lines: 1}
 	l.readChar()
 	return l
 }
@@ -37,6 +41,8 @@ func (l *Lexer) NextToken() token.Token {
 	case '=':
 		if l.peekChar() == '=' {
 			tok = tokenWithNext(l, token.EQ)
+		} else if l.peekChar() == '>' {
+			tok = tokenWithNext(l, token.ARROW)
 		} else {
 			tok = l.newToken(token.ASSIGN, l.char)
 		}
@@ -56,6 +62,12 @@ func (l *Lexer) NextToken() token.Token {
 		tok = l.newToken(token.LBRACKET, l.char)
 	case ']':
 		tok = l.newToken(token.RBRACKET, l.char)
+	case '.':
+		if strings.HasPrefix(l.input[l.current:], "...") {
+			tok = tokenFromRange(l, token.ELLIPSIS, 2)
+		} else {
+			tok = l.newToken(token.ILLEGAL, l.char)
+		}
 	case ':':
 		tok = l.newToken(token.COLON, l.char)
 	case '!':
@@ -69,7 +81,7 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Literal = l.readString()
 	case '/':
 		if l.peekChar() == '/' {
-			l.ignoreComment()
+			l.readComment()
 			return l.NextToken()
 		} else {
 			tok = l.newToken(token.SLASH, l.char)
@@ -124,10 +136,21 @@ func (l *Lexer) readString() string {
 	return l.input[position:l.current]
 }
 
-func (l *Lexer) ignoreComment() {
+// Comments returns the comments skipped by NextToken so far.
+// They are not part of the token stream but are kept
+// for tools that need to reproduce the source, e.g. a formatter.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
+}
+
+func (l *Lexer) readComment() {
+	tok := token.Token{Type: token.COMMENT, Line: l.Lines, Col: l.Col}
+	pos := l.current
 	for l.char != 0 && l.char != '\n' {
 		l.readChar()
 	}
+	tok.Literal = strings.TrimRight(l.input[pos:l.current], " \t\r")
+	l.comments = append(l.comments, tok)
 }
 
 // read without incrementing the next position
@@ -181,12 +204,13 @@ func tokenWithNext(l *Lexer, tokenType token.TokenType) token.Token {
 // return a token with the next r characters appended to the literal
 // and advance the position accordingly
 func tokenFromRange(l *Lexer, tokenType token.TokenType, r int) token.Token {
+	line, col := l.Lines, l.Col
 	literal := string(l.char)
 	for i := 0; i < r; i++ {
 		l.readChar()
 		literal += string(l.char)
 	}
-	return token.Token{Type: tokenType, Literal: literal}
+	return token.Token{Type: tokenType, Literal: literal, Line: line, Col: col}
 }
 
 type readUntilCallback func(char byte) bool
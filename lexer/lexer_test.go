@@ -35,6 +35,8 @@ if (5 < 10) {
 [1, 2];
 // this is a single line comment
 {"foo": "bar"}
+match (x) { _ => 1 }
+[...rest] ..
 `
 	tests := []struct {
 		expectedType    token.TokenType
@@ -135,6 +137,21 @@ if (5 < 10) {
 		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RBRACKET, "]"},
+		{token.ILLEGAL, "."},
+		{token.ILLEGAL, "."},
 		{token.EOF, ""},
 	}
 
@@ -168,3 +185,69 @@ func TestEmptyLexer(t *testing.T) {
 			"", tok.Literal)
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `let x = 5;
+  x >= 10;
+"foo"`
+	tests := []struct {
+		expectedType token.TokenType
+		expectedLine int
+		expectedCol  int
+	}{
+		{token.LET, 1, 1},
+		{token.IDENT, 1, 5},
+		{token.ASSIGN, 1, 7},
+		{token.INT, 1, 9},
+		{token.SEMICOLON, 1, 10},
+		{token.IDENT, 2, 3},
+		{token.GT_OR_EQ, 2, 5},
+		{token.INT, 2, 8},
+		{token.SEMICOLON, 2, 10},
+		{token.STRING, 3, 1},
+		{token.EOF, 3, 6},
+	}
+
+	l := NewLexer(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Line != tt.expectedLine || tok.Col != tt.expectedCol {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedCol, tok.Line, tok.Col)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// first
+let x = 5; // second
+// third`
+	l := NewLexer(input)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Type == token.COMMENT {
+			t.Fatalf("comment returned as token: %q", tok.Literal)
+		}
+	}
+	expected := []token.Token{
+		{Type: token.COMMENT, Literal: "// first", Line: 1, Col: 1},
+		{Type: token.COMMENT, Literal: "// second", Line: 2, Col: 12},
+		{Type: token.COMMENT, Literal: "// third", Line: 3, Col: 1},
+	}
+	comments := l.Comments()
+	if len(comments) != len(expected) {
+		t.Fatalf("wrong number of comments. expected=%d, got=%d",
+			len(expected), len(comments))
+	}
+	for i, c := range comments {
+		if c != expected[i] {
+			t.Errorf("comments[%d] wrong. expected=%+v, got=%+v", i, expected[i], c)
+		}
+	}
+}
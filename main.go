@@ -5,11 +5,9 @@ import (
 	"log"
 	"os"
 	"os/user"
+	"strconv"
 
 	"github.com/lindeneg/monkey/evaluator"
-	"github.com/lindeneg/monkey/lexer"
-	"github.com/lindeneg/monkey/object"
-	"github.com/lindeneg/monkey/parser"
 	"github.com/lindeneg/monkey/repl"
 )
 
@@ -22,21 +20,23 @@ import (
 // - Use the new object type in evaluation
 
 func main() {
-	if len(os.Args) > 1 {
-		data, err := os.ReadFile(os.Args[1])
+	// fix the seed of random and rand_int for reproducible runs
+	if seed := os.Getenv("MONKEY_SEED"); seed != "" {
+		n, err := strconv.ParseInt(seed, 10, 64)
 		if err != nil {
-			log.Fatal(err)
+			log.Fatalf("invalid MONKEY_SEED: %s", err)
 		}
-		env := object.NewEnvironment()
-		l := lexer.NewLexer(string(data))
-		p := parser.New(l)
-		program := p.ParseProgram()
-		if len(p.Errors()) > 0 {
-			repl.PrintParserErrors(os.Stdout, p.Errors())
+		evaluator.SeedRandom(n)
+	}
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
 		}
-		result := evaluator.Eval(program, env)
-		if result != nil && result.Type() == object.ERROR_OBJ {
-			fmt.Println(result.Inspect())
+		if err := runFile(os.Args[1:2]); err != nil {
+			log.Fatal(err)
 		}
 	} else {
 		u, err := user.Current()
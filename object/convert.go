@@ -0,0 +1,408 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Conversion between objects and Go values. Hashes and Go structs
+// are mapped by field name, or by the name given in a `monkey`
+// struct tag; fields tagged `monkey:"-"` are skipped.
+//
+//	INTEGER  int and uint types, int64 for interface{}
+//	STRING   string
+//	BOOLEAN  bool
+//	NULL     nil pointers, slices, maps and interfaces
+//	ARRAY    slices and arrays
+//	HASH     maps and structs, map[string]interface{} or
+//	         map[interface{}]interface{} for interface{}
+//
+// Object values are passed as they are both ways. A nil Object,
+// such as the result of evaluating a program ending in a let
+// statement, is treated as NULL.
+
+var objectType = reflect.TypeOf((*Object)(nil)).Elem()
+
+// ToGo converts obj to the Go value closest to it
+func ToGo(obj Object) (interface{}, error) {
+	switch obj := obj.(type) {
+	case *Null, nil:
+		return nil, nil
+	case *Integer:
+		return obj.Value, nil
+	case *String:
+		return obj.Value, nil
+	case *Boolean:
+		return obj.Value, nil
+	case *Array:
+		out := make([]interface{}, len(obj.Elements))
+		for i, el := range obj.Elements {
+			v, err := ToGo(el)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			out[i] = v
+		}
+		return out, nil
+	case *Hash:
+		return hashToGo(obj)
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a Go value", obj.Type())
+	}
+}
+
+// hashToGo converts hash to a map keyed by string if all
+// its keys are strings and by interface{} otherwise
+func hashToGo(hash *Hash) (interface{}, error) {
+	strKeys := true
+	for _, pair := range hash.pairs {
+		if pair.Key.Type() != STRING_OBJ {
+			strKeys = false
+			break
+		}
+	}
+	if strKeys {
+		out := make(map[string]interface{}, hash.Len())
+		for _, pair := range hash.pairs {
+			value, err := ToGo(pair.Value)
+			if err != nil {
+				return nil, fmt.Errorf("value of %s: %w", pair.Key.Inspect(), err)
+			}
+			out[pair.Key.(*String).Value] = value
+		}
+		return out, nil
+	}
+	out := make(map[interface{}]interface{}, hash.Len())
+	for _, pair := range hash.pairs {
+		key, err := ToGo(pair.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+		}
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, fmt.Errorf("key %s: %s keys are not supported",
+				pair.Key.Inspect(), pair.Key.Type())
+		}
+		value, err := ToGo(pair.Value)
+		if err != nil {
+			return nil, fmt.Errorf("value of %s: %w", pair.Key.Inspect(), err)
+		}
+		out[key] = value
+	}
+	return out, nil
+}
+
+// ToGoValue converts obj and stores the result in the value
+// pointed to by target, whose type decides the conversion
+func ToGoValue(obj Object, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("target must be a non-nil pointer")
+	}
+	return toGoValue(obj, v.Elem())
+}
+
+func toGoValue(obj Object, v reflect.Value) error {
+	if obj == nil {
+		obj = NULL
+	}
+	t := v.Type()
+	if t == objectType {
+		v.Set(reflect.ValueOf(&obj).Elem())
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := obj.(*Integer)
+		if !ok {
+			return mismatch(INTEGER_OBJ, obj)
+		}
+		if v.OverflowInt(integer.Value) {
+			return fmt.Errorf("%d overflows %s", integer.Value, t)
+		}
+		v.SetInt(integer.Value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		integer, ok := obj.(*Integer)
+		if !ok {
+			return mismatch(INTEGER_OBJ, obj)
+		}
+		if integer.Value < 0 || v.OverflowUint(uint64(integer.Value)) {
+			return fmt.Errorf("%d overflows %s", integer.Value, t)
+		}
+		v.SetUint(uint64(integer.Value))
+	case reflect.String:
+		str, ok := obj.(*String)
+		if !ok {
+			return mismatch(STRING_OBJ, obj)
+		}
+		v.SetString(str.Value)
+	case reflect.Bool:
+		boolean, ok := obj.(*Boolean)
+		if !ok {
+			return mismatch(BOOLEAN_OBJ, obj)
+		}
+		v.SetBool(boolean.Value)
+	case reflect.Slice:
+		if obj == NULL {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		arr, ok := obj.(*Array)
+		if !ok {
+			return mismatch(ARRAY_OBJ, obj)
+		}
+		v.Set(reflect.MakeSlice(t, len(arr.Elements), len(arr.Elements)))
+		for i, el := range arr.Elements {
+			if err := toGoValue(el, v.Index(i)); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+	case reflect.Array:
+		arr, ok := obj.(*Array)
+		if !ok {
+			return mismatch(ARRAY_OBJ, obj)
+		}
+		if len(arr.Elements) != t.Len() {
+			return fmt.Errorf("must be ARRAY of length %d, got %d", t.Len(), len(arr.Elements))
+		}
+		for i, el := range arr.Elements {
+			if err := toGoValue(el, v.Index(i)); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		if obj == NULL {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return mismatch(HASH_OBJ, obj)
+		}
+		v.Set(reflect.MakeMapWithSize(t, hash.Len()))
+		for _, pair := range hash.pairs {
+			key := reflect.New(t.Key()).Elem()
+			if err := toGoValue(pair.Key, key); err != nil {
+				return fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if err := toGoValue(pair.Value, value); err != nil {
+				return fmt.Errorf("value of %s: %w", pair.Key.Inspect(), err)
+			}
+			v.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return mismatch(HASH_OBJ, obj)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := fieldName(t.Field(i))
+			if !ok {
+				continue
+			}
+			value, ok := hash.Get(&String{Value: name})
+			if !ok {
+				continue
+			}
+			if err := toGoValue(value, v.Field(i)); err != nil {
+				return fmt.Errorf("field %s: %w", name, err)
+			}
+		}
+	case reflect.Pointer:
+		if obj == NULL {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		elem := reflect.New(t.Elem())
+		if err := toGoValue(obj, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			return fmt.Errorf("unsupported Go type %s", t)
+		}
+		native, err := ToGo(obj)
+		if err != nil {
+			return err
+		}
+		if native == nil {
+			v.Set(reflect.Zero(t))
+		} else {
+			v.Set(reflect.ValueOf(native))
+		}
+	default:
+		return fmt.Errorf("unsupported Go type %s", t)
+	}
+	return nil
+}
+
+// FromGo converts a Go value to an object. Maps are converted
+// with their keys sorted, so that the result is stable. A value
+// that refers back to itself cannot be converted.
+func FromGo(v interface{}) (Object, error) {
+	return fromGoValue(reflect.ValueOf(v), make(map[visit]bool))
+}
+
+// visit identifies a pointer, map or slice being converted.
+// Slices sharing an array differ in length, if at all.
+type visit struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+// fromGoValue converts v, where visiting holds the values
+// v is nested in, to detect cycles
+func fromGoValue(v reflect.Value, visiting map[visit]bool) (Object, error) {
+	if !v.IsValid() {
+		return NULL, nil
+	}
+	if v.Type().Implements(objectType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() ||
+			v.Kind() == reflect.Interface && v.IsNil() {
+			return NULL, nil
+		}
+		return v.Interface().(Object), nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		key := visit{typ: v.Type(), ptr: v.Pointer()}
+		if v.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		if visiting[key] {
+			return nil, fmt.Errorf("cycle through %s", v.Type())
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%d overflows INTEGER", v.Uint())
+		}
+		return &Integer{Value: int64(v.Uint())}, nil
+	case reflect.String:
+		return &String{Value: v.String()}, nil
+	case reflect.Bool:
+		if v.Bool() {
+			return TRUE, nil
+		}
+		return FALSE, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return NULL, nil
+		}
+		elements := make([]Object, v.Len())
+		for i := range elements {
+			el, err := fromGoValue(v.Index(i), visiting)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			elements[i] = el
+		}
+		return &Array{Elements: elements}, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		keys := v.MapKeys()
+		sortKeys(keys)
+		hash := NewHash()
+		for _, k := range keys {
+			key, err := fromGoValue(k, visiting)
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %w", k, err)
+			}
+			hashable, ok := HashableKey(key)
+			if !ok {
+				return nil, fmt.Errorf("key %v: unusable as hash key: %s", k, key.Type())
+			}
+			value, err := fromGoValue(v.MapIndex(k), visiting)
+			if err != nil {
+				return nil, fmt.Errorf("value of %v: %w", k, err)
+			}
+			hash.Set(hashable, value)
+		}
+		return hash, nil
+	case reflect.Struct:
+		hash := NewHash()
+		for i := 0; i < v.NumField(); i++ {
+			name, ok := fieldName(v.Type().Field(i))
+			if !ok {
+				continue
+			}
+			value, err := fromGoValue(v.Field(i), visiting)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+			hash.Set(&String{Value: name}, value)
+		}
+		return hash, nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		return fromGoValue(v.Elem(), visiting)
+	default:
+		return nil, fmt.Errorf("unsupported Go type %s", v.Type())
+	}
+}
+
+// fieldName returns the hash key of a struct field,
+// or false if the field is not converted
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("monkey")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+// sortKeys orders map keys of a basic kind
+func sortKeys(keys []reflect.Value) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Kind() == reflect.Interface {
+			a, b = a.Elem(), b.Elem()
+		}
+		if !a.IsValid() || !b.IsValid() || a.Kind() != b.Kind() {
+			return fmt.Sprint(a) < fmt.Sprint(b)
+		}
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		default:
+			return fmt.Sprint(a) < fmt.Sprint(b)
+		}
+	})
+}
+
+func mismatch(want ObjectType, got Object) error {
+	if got == nil {
+		got = NULL
+	}
+	return fmt.Errorf("must be %s, got %s", want, got.Type())
+}
@@ -0,0 +1,240 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToGo(t *testing.T) {
+	hash := NewHash()
+	hash.Set(&String{Value: "b"}, &Array{Elements: []Object{&Integer{Value: 1}, TRUE, NULL}})
+	hash.Set(&String{Value: "a"}, &String{Value: "x"})
+	mixed := NewHash()
+	mixed.Set(&Integer{Value: 1}, &String{Value: "one"})
+	mixed.Set(TRUE, FALSE)
+
+	tests := []struct {
+		input    Object
+		expected interface{}
+	}{
+		{&Integer{Value: 5}, int64(5)},
+		{&String{Value: "s"}, "s"},
+		{TRUE, true},
+		{NULL, nil},
+		{hash, map[string]interface{}{
+			"a": "x",
+			"b": []interface{}{int64(1), true, nil},
+		}},
+		{mixed, map[interface{}]interface{}{int64(1): "one", true: false}},
+	}
+	for _, tt := range tests {
+		actual, err := ToGo(tt.input)
+		if err != nil {
+			t.Errorf("ToGo(%s) returned error: %s", tt.input.Inspect(), err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("ToGo(%s): expected=%#v, got=%#v", tt.input.Inspect(), tt.expected, actual)
+		}
+	}
+
+	actual, err := ToGo(nil)
+	if err != nil || actual != nil {
+		t.Errorf("ToGo(nil): expected=<nil>, got=%#v (%v)", actual, err)
+	}
+	actual, err = ToGo(&Array{Elements: []Object{nil}})
+	if err != nil || !reflect.DeepEqual(actual, []interface{}{nil}) {
+		t.Errorf("ToGo([nil]): expected=[<nil>], got=%#v (%v)", actual, err)
+	}
+
+	_, err = ToGo(&Array{Elements: []Object{&Builtin{}}})
+	if err == nil || err.Error() != "element 0: cannot convert BUILTIN to a Go value" {
+		t.Errorf("wrong error for builtin, got %v", err)
+	}
+}
+
+type address struct {
+	City string `monkey:"city"`
+}
+
+type person struct {
+	Name     string   `monkey:"name"`
+	Age      int      `monkey:"age"`
+	Tags     []string `monkey:"tags"`
+	Address  *address `monkey:"address"`
+	Password string   `monkey:"-"`
+	Extra    map[string]int
+	Raw      Object
+	hidden   int
+}
+
+func TestToGoValue(t *testing.T) {
+	addr := NewHash()
+	addr.Set(&String{Value: "city"}, &String{Value: "Aarhus"})
+	extra := NewHash()
+	extra.Set(&String{Value: "x"}, &Integer{Value: 1})
+	input := NewHash()
+	input.Set(&String{Value: "name"}, &String{Value: "Ann"})
+	input.Set(&String{Value: "age"}, &Integer{Value: 30})
+	input.Set(&String{Value: "tags"}, &Array{Elements: []Object{&String{Value: "a"}}})
+	input.Set(&String{Value: "address"}, addr)
+	input.Set(&String{Value: "Password"}, &String{Value: "ignored"})
+	input.Set(&String{Value: "Extra"}, extra)
+	input.Set(&String{Value: "Raw"}, TRUE)
+
+	var p person
+	if err := ToGoValue(input, &p); err != nil {
+		t.Fatalf("ToGoValue returned error: %s", err)
+	}
+	expected := person{
+		Name:    "Ann",
+		Age:     30,
+		Tags:    []string{"a"},
+		Address: &address{City: "Aarhus"},
+		Extra:   map[string]int{"x": 1},
+		Raw:     TRUE,
+	}
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("expected=%+v, got=%+v", expected, p)
+	}
+
+	var raw Object = TRUE
+	if err := ToGoValue(nil, &raw); err != nil || raw != NULL {
+		t.Errorf("ToGoValue(nil, *Object): expected NULL, got %v (%v)", raw, err)
+	}
+	var pair [2]int
+	if err := ToGoValue(&Array{Elements: []Object{&Integer{Value: 1}, &Integer{Value: 2}}}, &pair); err != nil || pair != [2]int{1, 2} {
+		t.Errorf("ToGoValue([1, 2], *[2]int): expected [1 2], got %v (%v)", pair, err)
+	}
+	ptr := &address{}
+	if err := ToGoValue(nil, &ptr); err != nil || ptr != nil {
+		t.Errorf("ToGoValue(nil, **address): expected nil, got %v (%v)", ptr, err)
+	}
+	if err := ToGoValue(nil, new(int)); err == nil || err.Error() != "must be INTEGER, got NULL" {
+		t.Errorf("ToGoValue(nil, *int): expected error %q, got %v", "must be INTEGER, got NULL", err)
+	}
+
+	tests := []struct {
+		input    Object
+		target   interface{}
+		expected string
+	}{
+		{&String{Value: "1"}, new(int), "must be INTEGER, got STRING"},
+		{&Integer{Value: 300}, new(uint8), "300 overflows uint8"},
+		{&Integer{Value: -1}, new(uint), "-1 overflows uint"},
+		{&Array{Elements: []Object{TRUE}}, new([]int), "element 0: must be INTEGER, got BOOLEAN"},
+		{&Array{Elements: []Object{&Integer{Value: 1}}}, new([2]int), "must be ARRAY of length 2, got 1"},
+		{&Array{Elements: []Object{TRUE}}, new([1]int), "element 0: must be INTEGER, got BOOLEAN"},
+		{NULL, new([1]int), "must be ARRAY, got NULL"},
+		{input, new(map[string]string), "value of age: must be STRING, got INTEGER"},
+		{&Integer{Value: 1}, new(chan int), "unsupported Go type chan int"},
+		{&Integer{Value: 1}, 5, "target must be a non-nil pointer"},
+	}
+	for _, tt := range tests {
+		err := ToGoValue(tt.input, tt.target)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("ToGoValue(%s, %T): expected error %q, got %v",
+				tt.input.Inspect(), tt.target, tt.expected, err)
+		}
+	}
+}
+
+func TestFromGo(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{5, "5"},
+		{uint8(7), "7"},
+		{"s", "s"},
+		{true, "true"},
+		{nil, "null"},
+		{(*int)(nil), "null"},
+		{[]int(nil), "null"},
+		{[]interface{}{1, "a", []bool{false}}, "[1, a, [false]]"},
+		{[2]int{1, 2}, "[1, 2]"},
+		{map[string]int{"b": 2, "a": 1}, "{a: 1, b: 2}"},
+		{map[int]string{10: "x", 2: "y"}, "{2: y, 10: x}"},
+		{map[bool]int{true: 1, false: 0}, "{false: 0, true: 1}"},
+		{person{Name: "Ann", Age: 3, Password: "p", Address: &address{City: "X"}},
+			"{name: Ann, age: 3, tags: null, address: {city: X}, Extra: null, Raw: null}"},
+		{&Integer{Value: 9}, "9"},
+	}
+	for _, tt := range tests {
+		obj, err := FromGo(tt.input)
+		if err != nil {
+			t.Errorf("FromGo(%#v) returned error: %s", tt.input, err)
+			continue
+		}
+		if obj.Inspect() != tt.expected {
+			t.Errorf("FromGo(%#v): expected=%q, got=%q", tt.input, tt.expected, obj.Inspect())
+		}
+	}
+
+	if obj, _ := FromGo(false); obj != FALSE {
+		t.Errorf("FromGo(false) is not FALSE")
+	}
+	if _, err := FromGo(uint64(1 << 63)); err == nil {
+		t.Errorf("expected overflow error")
+	}
+	if _, err := FromGo(func() {}); err == nil {
+		t.Errorf("expected error for func")
+	}
+}
+
+func TestFromGoCycles(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	loop := &node{}
+	loop.Next = &node{Next: loop}
+	self := map[string]interface{}{}
+	self["self"] = self
+	nested := []interface{}{nil}
+	nested[0] = nested
+
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{loop, "field Next: field Next: cycle through *object.node"},
+		{self, "value of self: cycle through map[string]interface {}"},
+		{nested, "element 0: cycle through []interface {}"},
+	}
+	for _, tt := range tests {
+		_, err := FromGo(tt.input)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("FromGo(%T): expected error %q, got %v", tt.input, tt.expected, err)
+		}
+	}
+
+	// the same value reached twice without a cycle is converted twice
+	shared := &address{City: "X"}
+	obj, err := FromGo([]*address{shared, shared})
+	if err != nil {
+		t.Fatalf("FromGo returned error: %s", err)
+	}
+	if obj.Inspect() != "[{city: X}, {city: X}]" {
+		t.Errorf("expected=%q, got=%q", "[{city: X}, {city: X}]", obj.Inspect())
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := map[string]interface{}{
+		"list": []interface{}{int64(1), "two", nil},
+		"nested": map[string]interface{}{
+			"ok": true,
+		},
+	}
+	obj, err := FromGo(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := ToGo(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(input, output) {
+		t.Errorf("expected=%#v, got=%#v", input, output)
+	}
+}
@@ -11,9 +11,50 @@ func NewEnvironment() *Environment {
 	return &Environment{store: s, outer: nil}
 }
 
+// NewFileEnvironment returns the environment for evaluating the
+// file at path, imports in it are resolved relative to that file.
+// Modules are shared with the importing program, nil starts anew
+// with path as the entry file of the program.
+func NewFileEnvironment(path string, modules *Modules) *Environment {
+	if modules == nil {
+		modules = NewModules()
+		modules.entry = path
+	}
+	env := NewEnvironment()
+	env.file = path
+	env.modules = modules
+	return env
+}
+
 type Environment struct {
 	store map[string]Object
 	outer *Environment
+
+	// only set on the outermost environment
+	file    string
+	modules *Modules
+}
+
+func (e *Environment) root() *Environment {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e
+}
+
+// File returns the path of the file being evaluated,
+// or the empty string if the source is not from a file
+func (e *Environment) File() string {
+	return e.root().file
+}
+
+// Modules returns the modules loaded by the program
+func (e *Environment) Modules() *Modules {
+	root := e.root()
+	if root.modules == nil {
+		root.modules = NewModules()
+	}
+	return root.modules
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -28,3 +69,11 @@ func (e *Environment) Set(name string, val Object) Object {
 	e.store[name] = val
 	return val
 }
+
+// SetAll binds in e every binding made directly in other,
+// bindings of the environments enclosing other are skipped
+func (e *Environment) SetAll(other *Environment) {
+	for name, val := range other.store {
+		e.store[name] = val
+	}
+}
@@ -0,0 +1,68 @@
+package object
+
+import "fmt"
+
+// Module is the result of importing a file. Its top-level
+// bindings are read by indexing it with their name.
+type Module struct {
+	Name string // the path as written in the import
+	Path string // the absolute path of the file
+	Env  *Environment
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return fmt.Sprintf("module(%s)", m.Name) }
+
+// Member returns the top-level binding name of the module
+func (m *Module) Member(name string) (Object, bool) {
+	obj, ok := m.Env.store[name]
+	return obj, ok
+}
+
+// Modules keeps track of the modules loaded by a program,
+// keyed by absolute path, so that each file is evaluated
+// once and cyclic imports can be detected
+type Modules struct {
+	loaded  map[string]*Module
+	loading []string
+	entry   string
+}
+
+func NewModules() *Modules {
+	return &Modules{loaded: make(map[string]*Module)}
+}
+
+// Entry returns the path of the file the program started from,
+// or the empty string if it did not start from a file
+func (m *Modules) Entry() string {
+	return m.entry
+}
+
+// Get returns the module already loaded from path, if any
+func (m *Modules) Get(path string) (*Module, bool) {
+	mod, ok := m.loaded[path]
+	return mod, ok
+}
+
+// Begin records that the file at path is being loaded. If it
+// already is, the import is cyclic and Begin returns the chain
+// of paths leading back to it.
+func (m *Modules) Begin(path string) (cycle []string) {
+	for i, p := range m.loading {
+		if p == path {
+			cycle = append(cycle, m.loading[i:]...)
+			return append(cycle, path)
+		}
+	}
+	m.loading = append(m.loading, path)
+	return nil
+}
+
+// End records that loading the file at path is done, caching
+// mod if it is not nil, that is if loading succeeded
+func (m *Modules) End(path string, mod *Module) {
+	m.loading = m.loading[:len(m.loading)-1]
+	if mod != nil {
+		m.loaded[path] = mod
+	}
+}
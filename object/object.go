@@ -2,9 +2,12 @@ package object
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"hash"
 	"hash/fnv"
 	"strings"
+	"sync"
 
 	"github.com/lindeneg/monkey/ast"
 )
@@ -14,6 +17,7 @@ type ObjectType string
 const (
 	NULL_OBJ         ObjectType = "NULL"
 	ERROR_OBJ        ObjectType = "ERROR"
+	ERROR_VALUE_OBJ  ObjectType = "ERROR_VALUE"
 	INTEGER_OBJ      ObjectType = "INTEGER"
 	BOOLEAN_OBJ      ObjectType = "BOOLEAN"
 	RETURN_VALUE_OBJ ObjectType = "RETURN_VALUE"
@@ -22,6 +26,15 @@ const (
 	BUILTIN_OBJ      ObjectType = "BUILTIN"
 	ARRAY_OBJ        ObjectType = "ARRAY"
 	HASH_OBJ         ObjectType = "HASH"
+	MODULE_OBJ       ObjectType = "MODULE"
+)
+
+// The evaluator never creates other instances of Null and
+// Boolean than these, so they can be compared by identity
+var (
+	NULL  = &Null{}
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
 )
 
 type Object interface {
@@ -30,18 +43,32 @@ type Object interface {
 }
 
 type Hashable interface {
+	Object
 	HashKey() HashKey
 }
 
+// Error aborts evaluation until it is caught by a try expression.
+// Line is that of the innermost node the error was raised in, File
+// the file that node is in, and Stack lists the calls it unwound
+// through, innermost first.
 type Error struct {
 	Message string
+	Line    int
+	File    string // empty if the source is not from a file
+	Stack   []string
+	Value   Object // the thrown value, nil for errors not raised by throw
+}
+
+// ErrorValue is an error held as an ordinary value. Unlike
+// Error it does not abort evaluation, it is up to the script
+// to check for it.
+type ErrorValue struct {
+	Message string
 }
 
-// TODO HASH COLLISIONS
-// Chances that we experience it are low, but it
-// should be noted that there are well-known techniques such as “separate chaining” and “open
-// addressing” to work around the problem. Investigate it.
-// ALSO think about caching calculated hashes.
+// HashKey is the hashed form of a Hashable. Different keys
+// may hash to the same HashKey, Hash handles such collisions
+// by chaining the pairs and comparing the actual keys.
 type HashKey struct {
 	Type  ObjectType
 	Value uint64
@@ -49,8 +76,20 @@ type HashKey struct {
 
 type BuiltinFn func(args ...Object) Object
 
+// CallContext lets a builtin call back into the evaluator
+type CallContext interface {
+	// Call applies fn, a function or builtin, to args. Return
+	// values are unwrapped and errors returned as *Error.
+	Call(fn Object, args ...Object) Object
+}
+
+// ContextFn is a builtin taking functions as arguments
+type ContextFn func(ctx CallContext, args ...Object) Object
+
 type Builtin struct {
 	Fn BuiltinFn
+	// CtxFn is called instead of Fn if set
+	CtxFn ContextFn
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
@@ -63,15 +102,112 @@ func (e *Error) Type() ObjectType {
 	return ERROR_OBJ
 }
 
+func (e *ErrorValue) Type() ObjectType { return ERROR_VALUE_OBJ }
+func (e *ErrorValue) Inspect() string  { return "error: " + e.Message }
+
+// Hash keeps its pairs in insertion order while
+// still providing constant time lookup by key.
+// The zero value is an empty hash ready to use.
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	// positions in pairs of the keys sharing a HashKey
+	index map[HashKey][]int
+	pairs []HashPair
+}
+
+func NewHash() *Hash {
+	return &Hash{index: make(map[HashKey][]int)}
+}
+
+// find returns the position of key in pairs or -1
+func (h *Hash) find(hashed HashKey, key Hashable) int {
+	for _, i := range h.index[hashed] {
+		if keysEqual(h.pairs[i].Key, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Set associates value with key. Replacing the value
+// of an existing key keeps its original position.
+func (h *Hash) Set(key Hashable, value Object) {
+	if h.index == nil {
+		h.index = make(map[HashKey][]int)
+	}
+	hashed := key.HashKey()
+	if i := h.find(hashed, key); i >= 0 {
+		h.pairs[i] = HashPair{Key: key, Value: value}
+		return
+	}
+	h.index[hashed] = append(h.index[hashed], len(h.pairs))
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+}
+
+// Get returns the value associated with key, if any
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	i := h.find(key.HashKey(), key)
+	if i < 0 {
+		return nil, false
+	}
+	return h.pairs[i].Value, true
+}
+
+// Delete removes key from the hash, if present
+func (h *Hash) Delete(key Hashable) {
+	i := h.find(key.HashKey(), key)
+	if i < 0 {
+		return
+	}
+	pairs := h.pairs
+	h.index = make(map[HashKey][]int, len(pairs)-1)
+	h.pairs = make([]HashPair, 0, len(pairs)-1)
+	for j, pair := range pairs {
+		if j != i {
+			h.Set(pair.Key.(Hashable), pair.Value)
+		}
+	}
+}
+
+// Copy returns a shallow copy of the hash
+func (h *Hash) Copy() *Hash {
+	c := &Hash{
+		index: make(map[HashKey][]int, len(h.index)),
+		pairs: make([]HashPair, len(h.pairs)),
+	}
+	copy(c.pairs, h.pairs)
+	for k, v := range h.index {
+		c.index[k] = append([]int(nil), v...)
+	}
+	return c
+}
+
+// Pairs returns the pairs of the hash in insertion order.
+// The returned slice must not be modified.
+func (h *Hash) Pairs() []HashPair {
+	return h.pairs
+}
+
+func (h *Hash) Len() int { return len(h.pairs) }
+
+// HashKey combines the hashes of all pairs without regard to
+// their order, so hashes with the same pairs hash the same.
+// It is only meaningful if HashableKey accepts h.
+func (h *Hash) HashKey() HashKey {
+	var sum uint64
+	for _, pair := range h.pairs {
+		hasher := fnv.New64a()
+		writeHashKey(hasher, pair.Key)
+		writeHashKey(hasher, pair.Value)
+		sum += hasher.Sum64()
+	}
+	return HashKey{Type: h.Type(), Value: sum}
 }
 
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.pairs {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -85,6 +221,16 @@ type Array struct {
 	Elements []Object
 }
 
+// HashKey hashes the elements of the array in order.
+// It is only meaningful if HashableKey accepts ao.
+func (ao *Array) HashKey() HashKey {
+	hasher := fnv.New64a()
+	for _, e := range ao.Elements {
+		writeHashKey(hasher, e)
+	}
+	return HashKey{Type: ao.Type(), Value: hasher.Sum64()}
+}
+
 func (ao *Array) Type() ObjectType { return ARRAY_OBJ }
 func (ao *Array) Inspect() string {
 	var out bytes.Buffer
@@ -126,16 +272,25 @@ type HashPair struct {
 	Value Object
 }
 
+// String is immutable, Value must not be changed once
+// the string has been used as a hash key as the computed
+// hash is cached. The cache is safe for concurrent use.
 type String struct {
 	Value string
+
+	hashOnce sync.Once
+	hash     uint64
 }
 
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
 func (s *String) HashKey() HashKey {
-	h := fnv.New64a()
-	h.Write([]byte(s.Value))
-	return HashKey{Type: s.Type(), Value: h.Sum64()}
+	s.hashOnce.Do(func() {
+		h := fnv.New64a()
+		h.Write([]byte(s.Value))
+		s.hash = h.Sum64()
+	})
+	return HashKey{Type: s.Type(), Value: s.hash}
 }
 
 type Integer struct {
@@ -175,6 +330,85 @@ func (b *Boolean) HashKey() HashKey {
 	return HashKey{Type: b.Type(), Value: value}
 }
 
+// HashableKey returns obj as a Hashable if it can be used as a hash key.
+// Arrays and hashes can, as they are never modified once created, but
+// only when all of their elements, or values, can be used as keys too.
+func HashableKey(obj Object) (Hashable, bool) {
+	switch obj := obj.(type) {
+	case *Array:
+		for _, e := range obj.Elements {
+			if _, ok := HashableKey(e); !ok {
+				return nil, false
+			}
+		}
+		return obj, true
+	case *Hash:
+		for _, pair := range obj.pairs {
+			if _, ok := HashableKey(pair.Value); !ok {
+				return nil, false
+			}
+		}
+		return obj, true
+	case Hashable:
+		return obj, true
+	default:
+		return nil, false
+	}
+}
+
+// writeHashKey feeds the hash key of obj to hasher,
+// objects that are not hashable only contribute their type
+func writeHashKey(hasher hash.Hash64, obj Object) {
+	hasher.Write([]byte(obj.Type()))
+	if h, ok := obj.(Hashable); ok {
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], h.HashKey().Value)
+		hasher.Write(buf[:])
+	}
+}
+
+// keysEqual reports whether two hash keys are the same key.
+// Arrays and hashes are compared structurally, keys of
+// unknown types are only equal to themselves.
+func keysEqual(a, b Object) bool {
+	switch a := a.(type) {
+	case *Array:
+		b, ok := b.(*Array)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		for i, e := range a.Elements {
+			if !keysEqual(e, b.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		b, ok := b.(*Hash)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for _, pair := range a.pairs {
+			value, ok := b.Get(pair.Key.(Hashable))
+			if !ok || !keysEqual(pair.Value, value) {
+				return false
+			}
+		}
+		return true
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value == b.Value
+	case *Integer:
+		b, ok := b.(*Integer)
+		return ok && a.Value == b.Value
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		return ok && a.Value == b.Value
+	default:
+		return a == b
+	}
+}
+
 type ReturnValue struct {
 	Value Object
 }
@@ -17,3 +17,113 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestHashOrder(t *testing.T) {
+	h := NewHash()
+	h.Set(&String{Value: "b"}, &Integer{Value: 1})
+	h.Set(&Integer{Value: 7}, &Integer{Value: 2})
+	h.Set(&String{Value: "a"}, &Integer{Value: 3})
+	h.Set(&String{Value: "b"}, &Integer{Value: 4})
+
+	if h.Len() != 3 {
+		t.Fatalf("hash has wrong length. got=%d", h.Len())
+	}
+	if h.Inspect() != "{b: 4, 7: 2, a: 3}" {
+		t.Errorf("hash has wrong order. got=%q", h.Inspect())
+	}
+	value, ok := h.Get(&String{Value: "a"})
+	if !ok || value.Inspect() != "3" {
+		t.Errorf("wrong value for key a. got=%v", value)
+	}
+	if _, ok := h.Get(&String{Value: "z"}); ok {
+		t.Errorf("found value for missing key")
+	}
+}
+
+// collidingKey always hashes to the same HashKey
+type collidingKey struct {
+	name string
+}
+
+func (c *collidingKey) Type() ObjectType { return "COLLIDING" }
+func (c *collidingKey) Inspect() string  { return c.name }
+func (c *collidingKey) HashKey() HashKey {
+	return HashKey{Type: c.Type(), Value: 42}
+}
+
+func TestHashCollisions(t *testing.T) {
+	a := &collidingKey{name: "a"}
+	b := &collidingKey{name: "b"}
+	h := NewHash()
+	h.Set(a, &Integer{Value: 1})
+	h.Set(b, &Integer{Value: 2})
+	h.Set(a, &Integer{Value: 3})
+
+	if h.Len() != 2 {
+		t.Fatalf("colliding keys overwrote each other. got=%s", h.Inspect())
+	}
+	if value, ok := h.Get(a); !ok || value.Inspect() != "3" {
+		t.Errorf("wrong value for key a. got=%v", value)
+	}
+	if value, ok := h.Get(b); !ok || value.Inspect() != "2" {
+		t.Errorf("wrong value for key b. got=%v", value)
+	}
+	if _, ok := h.Get(&collidingKey{name: "c"}); ok {
+		t.Errorf("found value for missing colliding key")
+	}
+}
+
+func TestCompositeHashKey(t *testing.T) {
+	arr := func(elements ...Object) *Array { return &Array{Elements: elements} }
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+
+	if arr(one, two).HashKey() != arr(&Integer{Value: 1}, &Integer{Value: 2}).HashKey() {
+		t.Errorf("arrays with same elements have different hash keys")
+	}
+	if arr(one, two).HashKey() == arr(two, one).HashKey() {
+		t.Errorf("arrays with different order have same hash keys")
+	}
+	if arr(one).HashKey() == (&Hash{}).HashKey() {
+		t.Errorf("array and hash have same hash keys")
+	}
+
+	h1, h2 := NewHash(), NewHash()
+	h1.Set(&String{Value: "a"}, one)
+	h1.Set(&String{Value: "b"}, two)
+	h2.Set(&String{Value: "b"}, two)
+	h2.Set(&String{Value: "a"}, one)
+	if h1.HashKey() != h2.HashKey() || !keysEqual(h1, h2) {
+		t.Errorf("hashes with same pairs are not the same key")
+	}
+
+	if _, ok := HashableKey(arr(one, &Function{})); ok {
+		t.Errorf("array containing a function is hashable")
+	}
+	if _, ok := HashableKey(arr(arr(one), h1)); !ok {
+		t.Errorf("nested array is not hashable")
+	}
+}
+
+func TestStringHashKeyCached(t *testing.T) {
+	s := &String{Value: "cached"}
+	first := s.HashKey()
+	if s.hash != first.Value {
+		t.Fatalf("hash was not cached")
+	}
+	if second := s.HashKey(); first != second {
+		t.Errorf("cached hash differs. first=%v, second=%v", first, second)
+	}
+
+	// run with -race to check that concurrent use is safe
+	s = &String{Value: "shared"}
+	keys := make(chan HashKey)
+	for i := 0; i < 4; i++ {
+		go func() { keys <- s.HashKey() }()
+	}
+	want := (&String{Value: "shared"}).HashKey()
+	for i := 0; i < 4; i++ {
+		if got := <-keys; got != want {
+			t.Errorf("concurrent hash differs. expected=%v, got=%v", want, got)
+		}
+	}
+}
@@ -36,6 +36,15 @@ var precedences = map[token.TokenType]int{
 	token.LBRACKET: INDEX,
 }
 
+// Precedence returns the precedence of the infix operator t,
+// or LOWEST if t is not an infix operator.
+func Precedence(t token.TokenType) int {
+	if p, ok := precedences[t]; ok {
+		return p
+	}
+	return LOWEST
+}
+
 type (
 	prefixParseFn func() ast.Expression
 	infixParseFn  func(ast.Expression) ast.Expression
@@ -48,6 +57,11 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 
+	// comments read by the lexer so far, grouped
+	comments []*ast.CommentGroup
+	// number of lexer comments already grouped
+	commentCount int
+
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -71,6 +85,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.IMPORT, p.parseImportExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -96,7 +113,25 @@ func New(l *lexer.Lexer) *Parser {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
-
+	p.groupComments()
+}
+
+// groupComments groups the comments read by the lexer while
+// fetching the last token. Comments skipped in the same call
+// have no tokens between them, so a new group only starts
+// when a line is left empty.
+func (p *Parser) groupComments() {
+	comments := p.l.Comments()
+	var group *ast.CommentGroup
+	for _, tok := range comments[p.commentCount:] {
+		c := &ast.Comment{Token: tok}
+		if group == nil || group.List[len(group.List)-1].Token.Line+1 < tok.Line {
+			group = &ast.CommentGroup{}
+			p.comments = append(p.comments, group)
+		}
+		group.List = append(group.List, c)
+	}
+	p.commentCount = len(comments)
 }
 
 func (p *Parser) curTokenIs(t token.TokenType) bool {
@@ -129,7 +164,7 @@ func (p *Parser) peekError(t token.TokenType) {
 
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.curToken}
-	hash.Pairs = make(map[ast.Expression]ast.Expression)
+	hash.Pairs = []ast.HashPair{}
 	for !p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
 		key := p.parseExpression(LOWEST)
@@ -138,7 +173,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		}
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
-		hash.Pairs[key] = value
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
 		}
@@ -149,10 +184,35 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	return hash
 }
 
+// parseIndexExpression parses both left[index] and
+// left[start:end:step], the latter as a SliceExpression
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
-	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	tok := p.curToken
+	var start ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		start = p.parseExpression(LOWEST)
+	}
+	if !p.peekTokenIs(token.COLON) {
+		exp := &ast.IndexExpression{Token: tok, Left: left, Index: start}
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return exp
+	}
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+	p.nextToken() // consume first colon
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken() // consume second colon
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
@@ -175,6 +235,7 @@ func (p *Parser) ParseProgram() *ast.Program {
 		}
 		p.nextToken()
 	}
+	program.Comments = p.comments
 	return program
 }
 
@@ -184,6 +245,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -196,12 +259,17 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
-	if !p.expectPeek(token.IDENT) {
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		if stmt.Pattern = p.parsePattern(); stmt.Pattern == nil || !p.checkPatternNames(stmt.Pattern) {
+			return nil
+		}
+	} else if !p.expectPeek(token.IDENT) {
 		return nil
+	} else {
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	}
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -260,6 +328,16 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 
@@ -372,6 +450,235 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 	return exp
 }
 
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.curToken}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Block = p.parseBlockStatement()
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+		if !p.expectPeek(token.LPAREN) || !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		expression.Param = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.expectPeek(token.RPAREN) || !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		expression.Catch = p.parseBlockStatement()
+	}
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		expression.Finally = p.parseBlockStatement()
+	}
+	if expression.Catch == nil && expression.Finally == nil {
+		t := expression.Token
+		p.errors = append(p.errors, fmt.Sprintf(
+			"l:%d|c:%d -> try without catch or finally", t.Line, t.Col))
+		return nil
+	}
+	return expression
+}
+
+// parseMatchExpression parses match (subject) { pattern => body, ... }
+// where an arm may have a guard, pattern if condition => body, and
+// the body is an expression or a block. The comma after a block is
+// optional, as is the one after the last arm.
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) || !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Arms = []ast.MatchArm{}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := ast.MatchArm{Pattern: p.parsePattern()}
+		if arm.Pattern == nil || !p.checkPatternNames(arm.Pattern) {
+			return nil
+		}
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			p.nextToken()
+			arm.Guard = p.parseExpression(LOWEST)
+		}
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		p.nextToken()
+		block := p.curTokenIs(token.LBRACE)
+		if block {
+			arm.Body = p.parseBlockStatement()
+		} else {
+			arm.Body = p.parseExpression(LOWEST)
+		}
+		expression.Arms = append(expression.Arms, arm)
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if !block && !p.peekTokenIs(token.RBRACE) {
+			p.peekError(token.COMMA)
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return expression
+}
+
+// parsePattern parses the pattern starting at the current token
+func (p *Parser) parsePattern() ast.Expression {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return p.parseIdentifier()
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	default:
+		return p.parseLiteralPattern()
+	}
+}
+
+// checkPatternNames reports an error if pattern binds the same
+// name more than once, which _ is exempt from
+func (p *Parser) checkPatternNames(pattern ast.Expression) bool {
+	seen := map[string]bool{}
+	var check func(pattern ast.Expression) bool
+	bind := func(ident *ast.Identifier) bool {
+		if ident.Value == "_" {
+			return true
+		}
+		if seen[ident.Value] {
+			t := ident.Token
+			p.errors = append(p.errors, fmt.Sprintf(
+				"l:%d|c:%d -> duplicate name %s in pattern", t.Line, t.Col, ident.Value))
+			return false
+		}
+		seen[ident.Value] = true
+		return true
+	}
+	check = func(pattern ast.Expression) bool {
+		switch pattern := pattern.(type) {
+		case *ast.Identifier:
+			return bind(pattern)
+		case *ast.DefaultPattern:
+			return check(pattern.Pattern)
+		case *ast.ArrayPattern:
+			for _, el := range pattern.Elements {
+				if !check(el) {
+					return false
+				}
+			}
+			return pattern.Rest == nil || bind(pattern.Rest)
+		case *ast.HashPattern:
+			for _, pair := range pattern.Pairs {
+				if !check(pair.Value) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return check(pattern)
+}
+
+// parseLiteralPattern parses an integer, possibly negative,
+// a string or a boolean
+func (p *Parser) parseLiteralPattern() ast.Expression {
+	switch p.curToken.Type {
+	case token.INT:
+		return p.parseIntegerLiteral()
+	case token.STRING:
+		return p.parseStringLiteral()
+	case token.TRUE, token.FALSE:
+		return p.parseBoolean()
+	case token.MINUS:
+		if p.peekTokenIs(token.INT) {
+			return p.parsePrefixExpression()
+		}
+	}
+	t := p.curToken
+	p.errors = append(p.errors, fmt.Sprintf(
+		"l:%d|c:%d -> invalid pattern %s", t.Line, t.Col, t.Literal))
+	return nil
+}
+
+// parseElementPattern parses a pattern within an array or hash
+// pattern, which may be given a default as in pattern = default
+func (p *Parser) parseElementPattern() ast.Expression {
+	pattern := p.parsePattern()
+	if pattern == nil || !p.peekTokenIs(token.ASSIGN) {
+		return pattern
+	}
+	p.nextToken()
+	def := &ast.DefaultPattern{Token: p.curToken, Pattern: pattern}
+	p.nextToken()
+	def.Default = p.parseExpression(LOWEST)
+	return def
+}
+
+// parseArrayPattern parses [pattern, ...] where the last
+// element may instead be ...rest
+func (p *Parser) parseArrayPattern() ast.Expression {
+	pattern := &ast.ArrayPattern{Token: p.curToken}
+	pattern.Elements = []ast.Expression{}
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+		el := p.parseElementPattern()
+		if el == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, el)
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) parseHashPattern() ast.Expression {
+	pattern := &ast.HashPattern{Token: p.curToken}
+	pattern.Pairs = []ast.HashPair{}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseLiteralPattern()
+		if key == nil || !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		value := p.parseElementPattern()
+		if value == nil {
+			return nil
+		}
+		pattern.Pairs = append(pattern.Pairs, ast.HashPair{Key: key, Value: value})
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return pattern
+}
+
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.curToken}
 
@@ -467,6 +774,19 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	return identifiers
 }
 
+func (p *Parser) parseImportExpression() ast.Expression {
+	exp := &ast.ImportExpression{Token: p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	exp.Path = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return exp
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
@@ -42,6 +42,63 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = arr;", "let [a, b] = arr;"},
+		{"let [a, b, ...rest] = arr", "let [a, b, ...rest] = arr;"},
+		{"let [...all] = arr;", "let [...all] = arr;"},
+		{"let [a, b = 2 * a] = arr;", "let [a, b = (2 * a)] = arr;"},
+		{`let {"name": n, "age": a} = person;`, "let {name:n, age:a} = person;"},
+		{`let {"name": n = "anon", "pos": [x, y]} = p;`, "let {name:n = anon, pos:[x, y]} = p;"},
+		{`match (x) { [a, ...rest] => rest }`, "match (x) { [a, ...rest] => rest }"},
+		{"let [_, [_, ..._]] = arr;", "let [_, [_, ..._]] = arr;"},
+		{"let [a, b = a] = arr;", "let [a, b = a] = arr;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if ok && (stmt.Name != nil || stmt.Pattern == nil) {
+			t.Errorf("%q: expected a pattern and no name", tt.input)
+		}
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	for _, input := range []string{
+		"let [a, ...rest, b] = arr;",
+		"let [...] = arr;",
+		`let {n} = h;`,
+		`let {...rest} = h;`,
+		"let [a b] = arr;",
+		"let [a] arr;",
+		"let [a, a] = arr;",
+		"let [a, ...a] = arr;",
+		`let {"x": a, "y": [b, a = 1]} = h;`,
+	} {
+		p := New(lexer.NewLexer(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+
+	p := New(lexer.NewLexer("let [a, [b, a]] = arr;"))
+	p.ParseProgram()
+	expected := "l:1|c:13 -> duplicate name a in pattern"
+	if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+		t.Errorf("expected error %q, got %v", expected, p.Errors())
+	}
+}
+
 func TestParsingIndexExpressions(t *testing.T) {
 	input := "myArray[1 + 1]"
 
@@ -63,6 +120,142 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[1:2])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:2]", "(a[:2])"},
+		{"a[:]", "(a[:])"},
+		{"a[::2]", "(a[::2])"},
+		{"a[1:2:]", "(a[1:2])"},
+		{"a[-1:1 + 1:-1]", "(a[(-1):(1 + 1):(-1)])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.SliceExpression); !ok {
+			t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+		}
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestParsingImportExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import("lib.monkey")`, "import(lib.monkey)"},
+		{`import("lib/" + name)`, "import((lib/ + name))"},
+		{`import("lib.monkey")["map"](x)`, "(import(lib.monkey)[map])(x)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	p := New(lexer.NewLexer(`import "lib.monkey"`))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected parser errors for import without parentheses")
+	}
+}
+
+func TestParsingTryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`throw "bad";`, "throw bad;"},
+		{`throw {"message": m}`, "throw {message:m};"},
+		{`try { f(x) } catch (e) { e }`, "try f(x) catch (e) e"},
+		{`try { f(x) } finally { g() }`, "try f(x) finally g()"},
+		{`let r = try { 1 } catch (err) { throw err; } finally { 2 };`,
+			"let r = try 1 catch (err) throw err; finally 2;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	for _, input := range []string{`try { 1 }`, `try { 1 } catch { 2 }`, `try { 1 } catch (1) { 2 }`} {
+		p := New(lexer.NewLexer(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
+func TestParsingMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match (x) { 1 => "one", _ => "other" }`, "match (x) { 1 => one, _ => other }"},
+		{`match (x) { -1 => a, true => b, "s" => c, }`, "match (x) { (-1) => a, true => b, s => c }"},
+		{`match (f(x)) { [a, [b, _]] => a + b }`, "match (f(x)) { [a, [b, _]] => (a + b) }"},
+		{`match (x) { {"kind": k, 1: [v]} => k }`, "match (x) { {kind:k, 1:[v]} => k }"},
+		{`match (x) { n if n > 0 => n, n => -n }`, "match (x) { n if (n > 0) => n, n => (-n) }"},
+		{`match (x) { [] => { let y = 1; y } _ => 0 }`, "match (x) { [] => let y = 1;y, _ => 0 }"},
+		{`match (x) {}`, "match (x) {  }"},
+		{`let r = match (x) { _ => 1 } + 1;`, "let r = (match (x) { _ => 1 } + 1);"},
+		{`match (x) { [a, _, _] => a, [a] => a }`, "match (x) { [a, _, _] => a, [a] => a }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	for _, input := range []string{
+		`match x { _ => 1 }`,
+		`match (x) { _ => 1 _ => 2 }`,
+		`match (x) { f(y) => 1 }`,
+		`match (x) { {k: v} => 1 }`,
+		`match (x) { -y => 1 }`,
+		`match (x) { _ 1 }`,
+		`match (x) { [a, a] => a }`,
+		`match (x) { {"k": a, "l": {"m": a}} => a }`,
+	} {
+		p := New(lexer.NewLexer(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
 func TestParsingArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 	l := lexer.NewLexer(input)
@@ -112,7 +305,8 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		"two":   2,
 		"three": 3,
 	}
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
@@ -122,6 +316,32 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 	}
 }
 
+func TestParsingHashLiteralsOrder(t *testing.T) {
+	input := `{"c": 1, "a": 2, "b": 3, 1: 4}`
+	l := lexer.NewLexer(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+	expected := []string{"c", "a", "b", "1"}
+	if len(hash.Pairs) != len(expected) {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+	for i, pair := range hash.Pairs {
+		if pair.Key.String() != expected[i] {
+			t.Errorf("pair %d has wrong key. expected=%q, got=%q",
+				i, expected[i], pair.Key.String())
+		}
+	}
+	if hash.String() != "{c:1, a:2, b:3, 1:4}" {
+		t.Errorf("hash.String() wrong. got=%q", hash.String())
+	}
+}
+
 func TestParsingHashLiteralsBoolKeys(t *testing.T) {
 	input := `{true: 1, false: 2}`
 	l := lexer.NewLexer(input)
@@ -140,7 +360,8 @@ func TestParsingHashLiteralsBoolKeys(t *testing.T) {
 		true:  1,
 		false: 2,
 	}
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.Boolean)
 		if !ok {
 			t.Errorf("key is not ast.Boolean. got=%T", key)
@@ -168,7 +389,8 @@ func TestParsingHashLiteralsIntKeys(t *testing.T) {
 		1: "hello",
 		2: "there",
 	}
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.IntegerLiteral)
 		if !ok {
 			t.Errorf("key is not ast.IntegerLiteral. got=%T", key)
@@ -205,7 +427,8 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 			testInfixExpression(t, e, 15, "/", 5)
 		},
 	}
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
@@ -457,6 +680,10 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"-a * b",
 			"((-a) * b)",
 		},
+		{
+			"a[1:][0] * b[:2]",
+			"(((a[1:])[0]) * (b[:2]))",
+		},
 		{
 			"!-a",
 			"(!(-a))",
@@ -1035,3 +1262,51 @@ func checkParserErrors(t *testing.T, p *Parser) {
 	}
 	t.FailNow()
 }
+
+func TestParsingComments(t *testing.T) {
+	input := `// one
+// two
+
+// three
+let x = 5; // four
+// five
+x;`
+	l := lexer.NewLexer(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+	expected := [][]string{
+		{"// one", "// two"},
+		{"// three"},
+		{"// four", "// five"},
+	}
+	if len(program.Comments) != len(expected) {
+		t.Fatalf("wrong number of comment groups. expected=%d, got=%d",
+			len(expected), len(program.Comments))
+	}
+	for i, group := range program.Comments {
+		if len(group.List) != len(expected[i]) {
+			t.Errorf("group %d has wrong length. expected=%d, got=%d",
+				i, len(expected[i]), len(group.List))
+			continue
+		}
+		for j, c := range group.List {
+			if c.String() != expected[i][j] {
+				t.Errorf("comment %d.%d wrong. expected=%q, got=%q",
+					i, j, expected[i][j], c.String())
+			}
+		}
+	}
+	if program.Comments[2].List[0].Token.Line != 5 || program.Comments[2].List[0].Token.Col != 12 {
+		t.Errorf("comment has wrong position. got=%d:%d",
+			program.Comments[2].List[0].Token.Line, program.Comments[2].List[0].Token.Col)
+	}
+	if program.Comments[0].Text() != "one\ntwo" {
+		t.Errorf("group text wrong. got=%q", program.Comments[0].Text())
+	}
+}
@@ -13,6 +13,7 @@ package token
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT" // single line comment, never returned by the lexer
 
 	// Identifiers + literals
 	IDENT = "IDENT" // add, foobar, x, y, ...
@@ -28,7 +29,8 @@ const (
 	ASTERISK = "*"
 	SLASH    = "/"
 
-	COLON = ":"
+	COLON    = ":"
+	ELLIPSIS = "..."
 
 	LT = "<"
 	GT = ">"
@@ -39,6 +41,8 @@ const (
 	LT_OR_EQ = "<="
 	GT_OR_EQ = ">="
 
+	ARROW = "=>"
+
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
@@ -58,18 +62,30 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	IMPORT   = "IMPORT"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	THROW    = "THROW"
+	MATCH    = "MATCH"
 )
 
 type TokenType string
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":      FUNCTION,
+	"let":     LET,
+	"true":    TRUE,
+	"false":   FALSE,
+	"if":      IF,
+	"else":    ELSE,
+	"return":  RETURN,
+	"import":  IMPORT,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"throw":   THROW,
+	"match":   MATCH,
 }
 
 type Token struct {